@@ -0,0 +1,350 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/app"
+	"github.com/CodeOne45/vex-tui/internal/loader"
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Exit codes used by the headless subcommands
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// subcommands maps a subcommand name to its handler
+var subcommands = map[string]func(args []string) int{
+	"cat":     runCat,
+	"convert": runConvert,
+	"eval":    runEval,
+	"query":   runQuery,
+}
+
+// errUsage marks errors caused by invalid command-line usage
+var errUsage = errors.New("usage error")
+
+// runCat prints a sheet, or part of it, to stdout
+func runCat(args []string) int {
+	fs := flag.NewFlagSet("cat", flag.ContinueOnError)
+	sheetName := fs.String("sheet", "", "Sheet name or 1-based index (default: first sheet)")
+	cellRange := fs.String("range", "", "Cell range to print, e.g. A1:D20")
+	format := fs.String("format", "table", "Output format: table or any export extension (csv, tsv, json, md, ...)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: vex cat <file> [--sheet NAME] [--range A1:D20] [--format table|csv|tsv|json|md|...]")
+		fs.PrintDefaults()
+	}
+
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return exitCode(err)
+	}
+	if len(pos) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	sheets, err := loadSheets(pos[0])
+	if err != nil {
+		return fail("cat", err)
+	}
+	sheet, err := pickSheet(sheets, *sheetName)
+	if err != nil {
+		return fail("cat", err)
+	}
+	if *cellRange != "" {
+		if sheet, err = rangeSheet(sheet, *cellRange); err != nil {
+			return fail("cat", err)
+		}
+	}
+
+	if *format == "table" {
+		err = writeTable(os.Stdout, sheet)
+	} else {
+		err = loader.ExportTo(os.Stdout, sheet, *format)
+	}
+	if err != nil {
+		return fail("cat", err)
+	}
+	return exitOK
+}
+
+// runConvert converts a file to another format through the loader and exporters
+func runConvert(args []string) int {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	sheetName := fs.String("sheet", "", "Sheet to convert for single-sheet formats (default: first sheet)")
+	all := fs.Bool("all", false, "Convert every sheet (directory of CSV files, .json or SQLite target)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: vex convert <input> <output> [--sheet NAME] [--all]")
+		fs.PrintDefaults()
+	}
+
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return exitCode(err)
+	}
+	if len(pos) != 2 {
+		fs.Usage()
+		return exitUsage
+	}
+	input, output := pos[0], pos[1]
+
+	sheets, err := loadSheets(input)
+	if err != nil {
+		return fail("convert", err)
+	}
+
+	switch {
+	case *all:
+		_, err = loader.ExportWorkbook(sheets, output)
+	case isExcelFile(output):
+		err = loader.SaveExcel(sheets, output)
+	default:
+		var sheet models.Sheet
+		if sheet, err = pickSheet(sheets, *sheetName); err == nil {
+			err = loader.Export(sheet, output)
+		}
+	}
+	if err != nil {
+		return fail("convert", err)
+	}
+	return exitOK
+}
+
+// runEval evaluates a formula against a sheet and prints the result
+func runEval(args []string) int {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	sheetName := fs.String("sheet", "", "Sheet the formula refers to (default: first sheet)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: vex eval <file> <formula> [--sheet NAME]")
+		fs.PrintDefaults()
+	}
+
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return exitCode(err)
+	}
+	if len(pos) != 2 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	sheets, err := loadSheets(pos[0])
+	if err != nil {
+		return fail("eval", err)
+	}
+	sheet, err := pickSheet(sheets, *sheetName)
+	if err != nil {
+		return fail("eval", err)
+	}
+
+	formula := strings.TrimPrefix(strings.TrimSpace(pos[1]), "=")
+	result, err := app.NewFormulaEngine(&sheet).EvaluateStrict(formula)
+	if err != nil {
+		return fail("eval", err)
+	}
+	fmt.Println(result)
+	return exitOK
+}
+
+// runQuery runs SQL over the sheets of a file and prints the result
+func runQuery(args []string) int {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	format := fs.String("format", "table", "Output format: table or any export extension (csv, tsv, json, md, ...)")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: vex query <file> <sql> [--format table|csv|tsv|json|md|...]")
+		fs.PrintDefaults()
+	}
+
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return exitCode(err)
+	}
+	if len(pos) != 2 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	sheets, err := loadSheets(pos[0])
+	if err != nil {
+		return fail("query", err)
+	}
+	result, err := loader.QuerySheets(sheets, pos[1])
+	if err != nil {
+		return fail("query", err)
+	}
+
+	if *format == "table" {
+		err = writeTable(os.Stdout, result)
+	} else {
+		err = loader.ExportTo(os.Stdout, result, *format)
+	}
+	if err != nil {
+		return fail("query", err)
+	}
+	return exitOK
+}
+
+// isExcelFile reports whether filename has a writable Excel extension
+func isExcelFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx", ".xlsm":
+		return true
+	}
+	return false
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments and returns the positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos, nil
+		}
+		if args[0] == "--" {
+			return append(pos, args[1:]...), nil
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+}
+
+// exitCode maps a flag parsing error to an exit code
+func exitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// fail reports an error for a subcommand on stderr
+func fail(cmd string, err error) int {
+	fmt.Fprintf(os.Stderr, "vex %s: %v\n", cmd, err)
+	return exitError
+}
+
+// loadSheets validates and loads a file, reading lazily loaded sheets in full.
+// A file name of - reads CSV, TSV or JSON from stdin.
+func loadSheets(filename string) ([]models.Sheet, error) {
+	var (
+		sheets []models.Sheet
+		err    error
+	)
+	if filename == "-" {
+		sheets, err = loader.LoadReader(os.Stdin, "stdin")
+	} else if err = validateFile(filename); err == nil {
+		sheets, err = loader.LoadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("no sheets found in file")
+	}
+	for i := range sheets {
+		if err := loader.LoadAllRows(&sheets[i]); err != nil {
+			return nil, err
+		}
+	}
+	return sheets, nil
+}
+
+// pickSheet finds a sheet by name (case-insensitive) or 1-based index
+func pickSheet(sheets []models.Sheet, name string) (models.Sheet, error) {
+	if name == "" {
+		return sheets[0], nil
+	}
+	for _, sheet := range sheets {
+		if strings.EqualFold(sheet.Name, name) {
+			return sheet, nil
+		}
+	}
+	if idx, err := strconv.Atoi(name); err == nil && idx >= 1 && idx <= len(sheets) {
+		return sheets[idx-1], nil
+	}
+	return models.Sheet{}, fmt.Errorf("sheet %q not found", name)
+}
+
+// rangeSheet restricts a sheet to an A1:D20 style range
+func rangeSheet(sheet models.Sheet, cellRange string) (models.Sheet, error) {
+	startRow, startCol, endRow, endCol, err := ui.ParseRange(cellRange)
+	if err != nil {
+		return models.Sheet{}, err
+	}
+	rows := make([]int, 0, endRow-startRow+1)
+	for row := startRow; row <= endRow && row < sheet.MaxRows; row++ {
+		rows = append(rows, row)
+	}
+	return loader.SubSheet(sheet, rows, startCol, endCol), nil
+}
+
+// tableReplacer flattens whitespace that would break table alignment
+var tableReplacer = strings.NewReplacer("\n", " ", "\r", " ", "\t", " ")
+
+// writeTable prints a sheet as an aligned plain-text table with the first row
+// as header
+func writeTable(w io.Writer, sheet models.Sheet) error {
+	cols := sheet.MaxCols
+	for _, row := range sheet.Rows {
+		cols = ui.Max(cols, len(row))
+	}
+	if len(sheet.Rows) == 0 || cols == 0 {
+		return nil
+	}
+
+	widths := make([]int, cols)
+	cells := make([][]string, len(sheet.Rows))
+	for i, row := range sheet.Rows {
+		cells[i] = make([]string, cols)
+		for j := 0; j < cols && j < len(row); j++ {
+			value := tableReplacer.Replace(row[j].Value)
+			cells[i][j] = value
+			widths[j] = ui.Max(widths[j], lipgloss.Width(value))
+		}
+	}
+
+	var b strings.Builder
+	for i, row := range cells {
+		for j, value := range row {
+			if j > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(value)
+			if j < cols-1 {
+				b.WriteString(strings.Repeat(" ", widths[j]-lipgloss.Width(value)))
+			}
+		}
+		b.WriteString("\n")
+		if i == 0 && len(cells) > 1 {
+			for j, width := range widths {
+				if j > 0 {
+					b.WriteString("  ")
+				}
+				b.WriteString(strings.Repeat("─", ui.Max(width, 1)))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
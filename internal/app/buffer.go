@@ -0,0 +1,200 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/CodeOne45/vex-tui/internal/loader"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// buffer holds the state of an open file while another buffer is active.
+// The active buffer lives directly in the Model fields.
+type buffer struct {
+	filename      string
+	fileFormat    string
+	sheets        []models.Sheet
+	currentSheet  int
+	cursorRow     int
+	cursorCol     int
+	offsetRow     int
+	offsetCol     int
+	modified      bool
+	searchQuery   string
+	searchResults []models.SearchResult
+	searchIndex   int
+	searchOptions models.SearchOptions
+	selectStart   [2]int
+	selectEnd     [2]int
+	isSelecting   bool
+	panes         []pane
+	activePane    int
+	splitVertical bool
+	undoStack     []editStep
+	redoStack     []editStep
+	savedStep     int
+}
+
+// AddBuffer opens another file in the session without switching to it
+func (m *Model) AddBuffer(filename string, sheets []models.Sheet) {
+	m.storeBuffer()
+	m.buffers = append(m.buffers, buffer{
+		filename:   filename,
+		fileFormat: loader.FileFormat(filename),
+		sheets:     sheets,
+	})
+}
+
+// Close releases the files every buffer holds open, such as the connection
+// to a SQLite database, returning the first error
+func (m *Model) Close() error {
+	m.storeBuffer()
+	var first error
+	for _, b := range m.buffers {
+		if err := loader.CloseSheets(b.sheets); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// storeBuffer copies the active buffer state out of the model
+func (m *Model) storeBuffer() {
+	m.buffers[m.currentBuffer] = buffer{
+		filename:      m.filename,
+		fileFormat:    m.fileFormat,
+		sheets:        m.sheets,
+		currentSheet:  m.currentSheet,
+		cursorRow:     m.cursorRow,
+		cursorCol:     m.cursorCol,
+		offsetRow:     m.offsetRow,
+		offsetCol:     m.offsetCol,
+		modified:      m.modified,
+		searchQuery:   m.searchQuery,
+		searchResults: m.searchResults,
+		searchIndex:   m.searchIndex,
+		searchOptions: m.searchOptions,
+		selectStart:   m.selectStart,
+		selectEnd:     m.selectEnd,
+		isSelecting:   m.isSelecting,
+		panes:         m.panes,
+		activePane:    m.activePane,
+		splitVertical: m.splitVertical,
+		undoStack:     m.undoStack,
+		redoStack:     m.redoStack,
+		savedStep:     m.savedStep,
+	}
+}
+
+// switchBuffer makes buffer idx the active one
+func (m *Model) switchBuffer(idx int) {
+	if idx < 0 || idx >= len(m.buffers) || idx == m.currentBuffer {
+		return
+	}
+	m.storeBuffer()
+
+	b := m.buffers[idx]
+	m.currentBuffer = idx
+	m.filename = b.filename
+	m.fileFormat = b.fileFormat
+	m.sheets = b.sheets
+	m.currentSheet = b.currentSheet
+	m.cursorRow = b.cursorRow
+	m.cursorCol = b.cursorCol
+	m.offsetRow = b.offsetRow
+	m.offsetCol = b.offsetCol
+	m.modified = b.modified
+	m.searchQuery = b.searchQuery
+	m.searchResults = b.searchResults
+	m.searchIndex = b.searchIndex
+	m.searchOptions = b.searchOptions
+	m.selectStart = b.selectStart
+	m.selectEnd = b.selectEnd
+	m.isSelecting = b.isSelecting
+	m.panes = b.panes
+	m.activePane = b.activePane
+	m.splitVertical = b.splitVertical
+	m.undoStack = b.undoStack
+	m.redoStack = b.redoStack
+	m.savedStep = b.savedStep
+	m.quitConfirm = false
+	m.applyFilters()
+
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Buffer %d/%d: %s", idx+1, len(m.buffers), m.displayName()),
+		Type:    models.StatusInfo,
+	}
+}
+
+// cycleBuffer moves to the next (step 1) or previous (step -1) buffer
+func (m *Model) cycleBuffer(step int) {
+	if len(m.buffers) < 2 {
+		m.status = models.StatusMsg{Message: "Only one buffer open", Type: models.StatusInfo}
+		return
+	}
+	m.switchBuffer((m.currentBuffer + step + len(m.buffers)) % len(m.buffers))
+}
+
+// bufferModified reports whether buffer idx has unsaved changes
+func (m Model) bufferModified(idx int) bool {
+	if idx == m.currentBuffer {
+		return m.modified
+	}
+	return m.buffers[idx].modified
+}
+
+// modifiedBuffers returns the number of buffers with unsaved changes
+func (m Model) modifiedBuffers() int {
+	count := 0
+	for i := range m.buffers {
+		if m.bufferModified(i) {
+			count++
+		}
+	}
+	return count
+}
+
+// bufferName returns the short name of buffer idx for lists
+func (m Model) bufferName(idx int) string {
+	filename := m.buffers[idx].filename
+	if idx == m.currentBuffer {
+		filename = m.filename
+	}
+	if filename == "" {
+		return "[stdin]"
+	}
+	return filepath.Base(filename)
+}
+
+// openBufferList opens the buffer switcher on the active buffer
+func (m *Model) openBufferList() {
+	m.bufferCursor = m.currentBuffer
+	m.mode = models.ModeBuffers
+}
+
+// updateBuffers handles buffer list mode updates
+func (m Model) updateBuffers(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "B":
+		m.mode = models.ModeNormal
+	case "up", "k":
+		if m.bufferCursor > 0 {
+			m.bufferCursor--
+		}
+	case "down", "j":
+		if m.bufferCursor < len(m.buffers)-1 {
+			m.bufferCursor++
+		}
+	case "enter":
+		m.switchBuffer(m.bufferCursor)
+		m.mode = models.ModeNormal
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		idx := int(msg.String()[0] - '1')
+		if idx < len(m.buffers) {
+			m.switchBuffer(idx)
+			m.mode = models.ModeNormal
+		}
+	}
+	return m, nil
+}
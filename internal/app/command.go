@@ -0,0 +1,301 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/loader"
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updateCommand handles the : command prompt
+func (m Model) updateCommand(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEscape:
+		m.mode = models.ModeNormal
+		m.commandInput.Blur()
+		return m, nil
+
+	case tea.KeyEnter:
+		line := strings.TrimSpace(m.commandInput.Value())
+		m.mode = models.ModeNormal
+		m.commandInput.Blur()
+		if line != "" {
+			m.runCommand(line)
+		}
+		return m, nil
+	}
+
+	m.commandInput, cmd = m.commandInput.Update(msg)
+	return m, cmd
+}
+
+// runCommand executes a command line entered at the : prompt
+func (m *Model) runCommand(line string) {
+	name, args, _ := strings.Cut(line, " ")
+	args = strings.TrimSpace(args)
+
+	switch strings.ToLower(name) {
+	case "sort", "sort!":
+		// sort! includes the first row instead of keeping it as a header
+		keys, err := parseSortKeys(args)
+		if err != nil {
+			m.status = models.StatusMsg{Message: "Usage: :sort B desc, A (" + err.Error() + ")", Type: models.StatusWarning}
+			return
+		}
+		m.sortSheet(keys, !strings.HasSuffix(name, "!"))
+	case "filter":
+		if args == "" || strings.EqualFold(args, "off") {
+			m.clearFilters()
+			return
+		}
+		f, err := parseFilter(args)
+		if err != nil {
+			m.status = models.StatusMsg{Message: "Usage: :filter B > 100 (" + err.Error() + ")", Type: models.StatusWarning}
+			return
+		}
+		m.setColumnFilter(f.Col, &f)
+	case "nofilter":
+		m.clearFilters()
+	case "freeze":
+		if strings.EqualFold(args, "off") {
+			m.freezePanes(0, 0)
+			return
+		}
+		if args == "" {
+			m.freezePanes(m.cursorRow, m.cursorCol)
+			return
+		}
+		rows, cols, err := parseFreeze(args)
+		if err != nil {
+			m.status = models.StatusMsg{Message: "Usage: :freeze B2 or :freeze 1 [cols] (" + err.Error() + ")", Type: models.StatusWarning}
+			return
+		}
+		m.freezePanes(rows, cols)
+	case "unfreeze":
+		m.freezePanes(0, 0)
+	case "autofit", "fit":
+		if strings.EqualFold(args, "all") {
+			m.autoFit(0, ui.Max(0, m.sheets[m.currentSheet].MaxCols-1))
+			return
+		}
+		_, startCol, _, endCol := m.selectedRange()
+		m.autoFit(startCol, endCol)
+	case "height":
+		startRow, _, endRow, _ := m.selectedRange()
+		if strings.EqualFold(args, "auto") || args == "" {
+			m.setRowHeight(startRow, endRow, 0)
+			return
+		}
+		lines, err := strconv.Atoi(args)
+		if err != nil || lines < 1 {
+			m.status = models.StatusMsg{Message: "Usage: :height 3 or :height auto", Type: models.StatusWarning}
+			return
+		}
+		m.setRowHeight(startRow, endRow, lines)
+	case "wrap":
+		m.toggleWrap()
+	case "split", "sp", "vsplit", "vs":
+		sheet := -1
+		if args != "" {
+			if sheet = m.sheetIndex(args); sheet < 0 {
+				if n, err := strconv.Atoi(args); err == nil && n >= 1 && n <= len(m.sheets) {
+					sheet = n - 1
+				}
+			}
+			if sheet < 0 {
+				m.status = models.StatusMsg{Message: fmt.Sprintf("No sheet named %q", args), Type: models.StatusWarning}
+				return
+			}
+		}
+		m.splitWindow(strings.HasPrefix(strings.ToLower(name), "v"), sheet)
+	case "hide", "unhide":
+		m.hideCommand(strings.EqualFold(name, "hide"), args)
+	case "group", "ungroup":
+		cols, err := parseOutlineAxis(args)
+		if err != nil {
+			m.status = models.StatusMsg{Message: "Usage: :" + strings.ToLower(name) + " [rows|cols] (" + err.Error() + ")", Type: models.StatusWarning}
+			return
+		}
+		delta := 1
+		if strings.EqualFold(name, "ungroup") {
+			delta = -1
+		}
+		m.groupSelection(cols, delta)
+	case "collapse", "expand":
+		cols, err := parseOutlineAxis(args)
+		if err != nil {
+			m.status = models.StatusMsg{Message: "Usage: :" + strings.ToLower(name) + " [rows|cols] (" + err.Error() + ")", Type: models.StatusWarning}
+			return
+		}
+		if strings.EqualFold(name, "collapse") {
+			m.collapseGroup(cols)
+		} else {
+			m.expandGroup(cols)
+		}
+	case "outline":
+		levelArg, axisArg, _ := strings.Cut(args, " ")
+		level, err := strconv.Atoi(levelArg)
+		cols, axisErr := parseOutlineAxis(strings.TrimSpace(axisArg))
+		if err != nil || level < 1 || level > maxOutlineLevel+1 || axisErr != nil {
+			m.status = models.StatusMsg{Message: fmt.Sprintf("Usage: :outline 1-%d [rows|cols]", maxOutlineLevel+1), Type: models.StatusWarning}
+			return
+		}
+		m.showOutlineLevel(level, cols)
+	case "comment":
+		// \n in the text breaks the comment into lines
+		if args == "" {
+			m.status = models.StatusMsg{Message: "Usage: :comment text (\\n for a new line), or :nocomment to delete", Type: models.StatusWarning}
+			return
+		}
+		m.setComment(strings.ReplaceAll(args, `\n`, "\n"))
+	case "nocomment", "uncomment":
+		m.setComment("")
+	case "validate":
+		rule, err := parseValidation(args)
+		if err != nil {
+			m.status = models.StatusMsg{Message: "Usage: :validate list a,b,c | list =E1:E9 | whole 1 10 | decimal >= 0 | date 2024-01-01 2024-12-31 | time 9:00 17:00 | length <= 20 (" + err.Error() + ")", Type: models.StatusWarning}
+			return
+		}
+		m.setValidation(&rule)
+	case "novalidate":
+		m.setValidation(nil)
+	case "close":
+		m.closePane()
+	case "only":
+		m.onlyPane()
+	case "sql":
+		if args == "" {
+			m.status = models.StatusMsg{Message: "Usage: :sql SELECT ...", Type: models.StatusWarning}
+			return
+		}
+		m.runQuery(args)
+	default:
+		m.status = models.StatusMsg{
+			Message: fmt.Sprintf("Unknown command: %s (available: autofit, close, collapse, comment, expand, filter, freeze, group, height, hide, nocomment, nofilter, novalidate, only, outline, sort, split, sql, unfreeze, ungroup, unhide, validate, vsplit, wrap)", name),
+			Type:    models.StatusError,
+		}
+	}
+}
+
+// runQuery runs SQL over the sheets of every open file and opens the result
+// as a new read-only sheet
+func (m *Model) runQuery(query string) {
+	if !m.loadQuerySheets() {
+		return
+	}
+	sheets, tables := m.queryTables()
+
+	result, err := loader.QueryNamedSheets(sheets, tables, query)
+	if err != nil {
+		m.status = models.StatusMsg{Message: err.Error(), Type: models.StatusError}
+		return
+	}
+
+	result.Name = m.uniqueSheetName("Query")
+	m.sheets = append(m.sheets, result)
+	m.currentSheet = len(m.sheets) - 1
+	m.resetView()
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Query returned %d rows", ui.Max(0, result.MaxRows-1)),
+		Type:    models.StatusSuccess,
+	}
+}
+
+// uniqueSheetName returns base, or base followed by a number if a sheet of
+// that name already exists
+func (m Model) uniqueSheetName(base string) string {
+	name := base
+	for n := 2; m.sheetIndex(name) >= 0; n++ {
+		name = fmt.Sprintf("%s %d", base, n)
+	}
+	return name
+}
+
+// sheetIndex returns the index of the sheet with the given name, or -1
+func (m Model) sheetIndex(name string) int {
+	for i, sheet := range m.sheets {
+		if strings.EqualFold(sheet.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadQuerySheets loads the rows of every sheet of every open file for :sql
+func (m *Model) loadQuerySheets() bool {
+	if !m.loadAllRows(m.allSheetIndexes()...) {
+		return false
+	}
+	for i := range m.buffers {
+		if i == m.currentBuffer {
+			continue
+		}
+		for j := range m.buffers[i].sheets {
+			if err := loader.LoadAllRows(&m.buffers[i].sheets[j]); err != nil {
+				m.status = models.StatusMsg{Message: fmt.Sprintf("Load failed: %v", err), Type: models.StatusError}
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// queryTables returns the sheets of every open file with their table names
+// for :sql. The active file's sheets keep their own names; a sheet of another
+// file whose name is taken is prefixed with the file name, as in
+// costs_Sheet1.
+func (m Model) queryTables() ([]models.Sheet, []string) {
+	sheets := append([]models.Sheet(nil), m.sheets...)
+	tables := loader.QueryTables(m.sheets)
+	used := make(map[string]bool)
+	for _, table := range tables {
+		used[strings.ToLower(table)] = true
+	}
+
+	for i, b := range m.buffers {
+		if i == m.currentBuffer {
+			continue
+		}
+		file := m.bufferName(i)
+		file = strings.TrimSuffix(file, filepath.Ext(file))
+		for j, name := range loader.QueryTables(b.sheets) {
+			if name == "" {
+				continue
+			}
+			table := name
+			if used[strings.ToLower(table)] {
+				table = loader.SQLIdentifier(file + "_" + name)
+			}
+			base := table
+			for n := 2; used[strings.ToLower(table)]; n++ {
+				table = fmt.Sprintf("%s_%d", base, n)
+			}
+			used[strings.ToLower(table)] = true
+			sheets = append(sheets, b.sheets[j])
+			tables = append(tables, table)
+		}
+	}
+	return sheets, tables
+}
+
+// queryTablesHint lists the tables available to :sql
+func (m Model) queryTablesHint() string {
+	_, names := m.queryTables()
+	var tables []string
+	for _, table := range names {
+		if table != "" {
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) == 0 {
+		return "No tables"
+	}
+	return "Tables: " + strings.Join(tables, ", ")
+}
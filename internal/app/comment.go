@@ -0,0 +1,85 @@
+package app
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commentAuthor returns the name new comments are signed with: the login
+// name, or vex when there is none
+func commentAuthor() string {
+	for _, name := range []string{"USER", "USERNAME"} {
+		if user := os.Getenv(name); user != "" {
+			return user
+		}
+	}
+	return "vex"
+}
+
+// cursorComment returns the comment on the cursor cell, if any
+func (m Model) cursorComment() string {
+	sheet := m.sheets[m.currentSheet]
+	return cellOrEmpty(&sheet, m.cursorRow, m.cursorCol).Comment
+}
+
+// setComment attaches a comment to the cursor cell, replacing any comment it
+// has, or removes it when text is empty. It is one undo step.
+func (m *Model) setComment(text string) {
+	if !m.canEdit() {
+		return
+	}
+	sheet := &m.sheets[m.currentSheet]
+	ref := ui.ColIndexToLetter(m.cursorCol) + strconv.Itoa(m.cursorRow+1)
+	had := m.cursorComment() != ""
+	if text == "" && !had {
+		m.status = models.StatusMsg{Message: "No comment on " + ref, Type: models.StatusInfo}
+		return
+	}
+
+	label := "comment " + ref
+	if text == "" {
+		label = "delete comment " + ref
+	}
+	step := m.newStep(label, stepCells)
+	step.snapshot(sheet, m.cursorRow, m.cursorCol, m.cursorRow, m.cursorCol)
+	cell := ensureCell(sheet, m.cursorRow, m.cursorCol)
+	cell.Comment = text
+	switch {
+	case text == "":
+		cell.CommentAuthor = ""
+	case cell.CommentAuthor == "":
+		cell.CommentAuthor = commentAuthor()
+	}
+	if !m.recordStep(step) {
+		m.status = models.StatusMsg{Message: "Comment unchanged", Type: models.StatusInfo}
+		return
+	}
+
+	switch {
+	case text == "":
+		m.status = models.StatusMsg{Message: "Deleted comment on " + ref, Type: models.StatusSuccess}
+	case had:
+		m.status = models.StatusMsg{Message: "Edited comment on " + ref, Type: models.StatusSuccess}
+	default:
+		m.status = models.StatusMsg{Message: "Added comment to " + ref, Type: models.StatusSuccess}
+	}
+}
+
+// editComment opens the command prompt with the cursor cell's comment ready
+// to be edited, its line breaks written as \n
+func (m *Model) editComment() tea.Cmd {
+	if !m.canEdit() {
+		return nil
+	}
+	m.mode = models.ModeCommand
+	m.commandInput.SetValue("comment " + strings.ReplaceAll(m.cursorComment(), "\n", `\n`))
+	m.commandInput.CursorEnd()
+	m.commandInput.Focus()
+	return textinput.Blink
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/CodeOne45/vex-tui/internal/theme"
 	"github.com/CodeOne45/vex-tui/internal/ui"
 	"github.com/CodeOne45/vex-tui/pkg/models"
-	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -27,6 +26,9 @@ func (m Model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyEnter:
+		if !m.validateEdit() {
+			return m, nil
+		}
 		m.commitEdit()
 		m.mode = models.ModeNormal
 		m.editInput.Blur()
@@ -34,26 +36,31 @@ func (m Model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyTab:
+		if !m.validateEdit() {
+			return m, nil
+		}
 		m.commitEdit()
-		sheet := m.sheets[m.currentSheet]
-		if m.cursorCol < sheet.MaxCols-1 {
-			m.cursorCol++
-		} else if m.cursorRow < sheet.MaxRows-1 {
-			m.cursorCol = 0
-			m.cursorRow++
+		if col := m.shownCol(m.cursorCol+1, 1); col >= 0 {
+			m.cursorCol = col
+		} else if m.rowPosition(m.cursorRow) < m.shownRows()-1 {
+			m.cursorCol = m.shownCol(0, 1)
+			m.moveCursorRows(1)
 		}
 		m.adjustViewport()
 		m.startEdit()
 		return m, textinput.Blink
 
 	case tea.KeyShiftTab:
+		if !m.validateEdit() {
+			return m, nil
+		}
 		m.commitEdit()
-		if m.cursorCol > 0 {
-			m.cursorCol--
-		} else if m.cursorRow > 0 {
+		if col := m.shownCol(m.cursorCol-1, -1); col >= 0 {
+			m.cursorCol = col
+		} else if m.rowPosition(m.cursorRow) > 0 {
 			sheet := m.sheets[m.currentSheet]
-			m.cursorCol = sheet.MaxCols - 1
-			m.cursorRow--
+			m.cursorCol = m.shownCol(sheet.MaxCols-1, -1)
+			m.moveCursorRows(-1)
 		}
 		m.adjustViewport()
 		m.startEdit()
@@ -64,14 +71,55 @@ func (m Model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.editInput.Blur()
 		m.isEditing = false
 		return m, nil
+
+	case tea.KeyUp, tea.KeyDown:
+		// Arrows move through the choices of a list validation
+		if len(m.pickChoices) > 0 {
+			step := 1
+			if msg.Type == tea.KeyUp {
+				step = -1
+			}
+			m.movePicker(step)
+			return m, nil
+		}
 	}
 
 	m.editInput, cmd = m.editInput.Update(msg)
+	if len(m.pickChoices) > 0 {
+		m.syncPicker()
+	}
 	return m, cmd
 }
 
+// canEdit reports whether cells of the current sheet may be changed and sets
+// a status message when they may not
+func (m *Model) canEdit() bool {
+	if m.sheets[m.currentSheet].ReadOnly {
+		m.status = models.StatusMsg{Message: "Sheet is read-only", Type: models.StatusWarning}
+		return false
+	}
+	return true
+}
+
+// canReshape reports whether rows and columns of the current sheet may be
+// inserted or deleted. Sheets paged from a database only accept cell edits.
+func (m *Model) canReshape() bool {
+	if !m.canEdit() {
+		return false
+	}
+	if m.sheets[m.currentSheet].Source != nil {
+		m.status = models.StatusMsg{Message: "Rows and columns of database tables cannot be inserted or deleted", Type: models.StatusWarning}
+		return false
+	}
+	return true
+}
+
 // startEdit initializes edit mode for current cell
 func (m *Model) startEdit() {
+	if !m.canEdit() {
+		return
+	}
+
 	sheet := &m.sheets[m.currentSheet]
 	if m.cursorRow >= len(sheet.Rows) {
 		sheet.Rows = append(sheet.Rows, make([][]models.Cell, m.cursorRow-len(sheet.Rows)+1)...)
@@ -102,13 +150,15 @@ func (m *Model) startEdit() {
 	m.editInput.Focus()
 	m.isEditing = true
 	m.mode = models.ModeEdit
-	m.modified = true
+	m.startPicker()
 }
 
 // commitEdit saves the current edit to the cell
 func (m *Model) commitEdit() {
 	sheet := &m.sheets[m.currentSheet]
 	value := strings.TrimSpace(m.editInput.Value())
+	step := m.newStep("edit "+ui.ColIndexToLetter(m.cursorCol)+strconv.Itoa(m.cursorRow+1), stepCells)
+	step.snapshot(sheet, m.cursorRow, m.cursorCol, m.cursorRow, m.cursorCol)
 
 	if m.cursorRow >= len(sheet.Rows) {
 		for i := len(sheet.Rows); i <= m.cursorRow; i++ {
@@ -137,36 +187,59 @@ func (m *Model) commitEdit() {
 		cell.Formula = ""
 	}
 
-	m.modified = true
 	m.recalculateFormulas()
+	m.recordStep(step)
 }
 
-// deleteCell deletes content of current cell
+// deleteCell deletes content of current cell, or of the whole selection
 func (m *Model) deleteCell() {
+	if !m.canEdit() {
+		return
+	}
+	if m.isSelecting {
+		startRow, startCol, endRow, endCol := m.selectedRange()
+		if m.clearRange("clear range") {
+			m.status = models.StatusMsg{
+				Message: fmt.Sprintf("Cleared %d×%d range", endRow-startRow+1, endCol-startCol+1),
+				Type:    models.StatusSuccess,
+			}
+		}
+		return
+	}
+
 	sheet := &m.sheets[m.currentSheet]
 	if m.cursorRow < len(sheet.Rows) && m.cursorCol < len(sheet.Rows[m.cursorRow]) {
+		step := m.newStep("clear cell", stepCells)
+		step.snapshot(sheet, m.cursorRow, m.cursorCol, m.cursorRow, m.cursorCol)
 		cell := &sheet.Rows[m.cursorRow][m.cursorCol]
 		cell.Value = ""
 		cell.Formula = ""
-		m.modified = true
 		m.recalculateFormulas()
+		m.recordStep(step)
 		m.status = models.StatusMsg{Message: "Cell cleared", Type: models.StatusSuccess}
 	}
 }
 
 // deleteRow deletes entire current row
 func (m *Model) deleteRow() {
+	if !m.canReshape() {
+		return
+	}
+
 	sheet := &m.sheets[m.currentSheet]
 	if m.cursorRow < len(sheet.Rows) {
-		sheet.Rows = append(sheet.Rows[:m.cursorRow], sheet.Rows[m.cursorRow+1:]...)
-		sheet.MaxRows = len(sheet.Rows)
+		deleted := m.cursorRow
+		step := m.newStep(fmt.Sprintf("delete row %d", deleted+1), stepDeleteRow)
+		step.index = deleted
+		step.cells = removeRow(sheet, deleted)
 		if m.cursorRow >= sheet.MaxRows && sheet.MaxRows > 0 {
 			m.cursorRow = sheet.MaxRows - 1
 		}
-		m.modified = true
 		m.recalculateFormulas()
+		m.applyFilters()
+		m.recordStep(step)
 		m.status = models.StatusMsg{
-			Message: fmt.Sprintf("Row %d deleted", m.cursorRow+1),
+			Message: fmt.Sprintf("Row %d deleted (u to undo)", deleted+1),
 			Type:    models.StatusSuccess,
 		}
 	}
@@ -174,42 +247,48 @@ func (m *Model) deleteRow() {
 
 // deleteColumn deletes entire current column
 func (m *Model) deleteColumn() {
+	if !m.canReshape() {
+		return
+	}
+
 	sheet := &m.sheets[m.currentSheet]
-	for i := range sheet.Rows {
-		if m.cursorCol < len(sheet.Rows[i]) {
-			sheet.Rows[i] = append(sheet.Rows[i][:m.cursorCol], sheet.Rows[i][m.cursorCol+1:]...)
+	deleted := m.cursorCol
+	step := m.newStep("delete column "+ui.ColIndexToLetter(deleted), stepDeleteCol)
+	step.index = deleted
+	for _, f := range sheet.Filters {
+		if f.Col == deleted {
+			step.filters = append(step.filters, f)
 		}
 	}
-	sheet.MaxCols--
+	step.cells, step.present = removeColumn(sheet, deleted)
+	sheet.Sort = nil
 	if m.cursorCol >= sheet.MaxCols && sheet.MaxCols > 0 {
 		m.cursorCol = sheet.MaxCols - 1
 	}
-	m.modified = true
 	m.recalculateFormulas()
+	m.applyFilters()
+	m.recordStep(step)
 	m.status = models.StatusMsg{
-		Message: fmt.Sprintf("Column %s deleted", ui.ColIndexToLetter(m.cursorCol)),
+		Message: fmt.Sprintf("Column %s deleted (u to undo)", ui.ColIndexToLetter(deleted)),
 		Type:    models.StatusSuccess,
 	}
 }
 
 // insertRow inserts a new row at current position
 func (m *Model) insertRow() {
-	sheet := &m.sheets[m.currentSheet]
-	newRow := make([]models.Cell, sheet.MaxCols)
-	for i := range newRow {
-		newRow[i] = models.Cell{Row: m.cursorRow, Col: i}
+	if !m.canReshape() {
+		return
 	}
-	sheet.Rows = append(sheet.Rows[:m.cursorRow], append([][]models.Cell{newRow}, sheet.Rows[m.cursorRow:]...)...)
-	sheet.MaxRows = len(sheet.Rows)
 
-	for i := m.cursorRow + 1; i < len(sheet.Rows); i++ {
-		for j := range sheet.Rows[i] {
-			sheet.Rows[i][j].Row = i
-		}
-	}
+	sheet := &m.sheets[m.currentSheet]
+	step := m.newStep(fmt.Sprintf("insert row %d", m.cursorRow+1), stepInsertRow)
+	step.index = ui.Min(m.cursorRow, len(sheet.Rows))
+	step.cells = make([]models.Cell, sheet.MaxCols)
+	insertRowAt(sheet, step.index, step.cells)
 
-	m.modified = true
 	m.recalculateFormulas()
+	m.applyFilters()
+	m.recordStep(step)
 	m.status = models.StatusMsg{
 		Message: fmt.Sprintf("Row inserted at %d", m.cursorRow+1),
 		Type:    models.StatusSuccess,
@@ -218,110 +297,182 @@ func (m *Model) insertRow() {
 
 // insertColumn inserts a new column at current position
 func (m *Model) insertColumn() {
-	sheet := &m.sheets[m.currentSheet]
-	for i := range sheet.Rows {
-		newCell := models.Cell{Row: i, Col: m.cursorCol}
-		sheet.Rows[i] = append(sheet.Rows[i][:m.cursorCol], append([]models.Cell{newCell}, sheet.Rows[i][m.cursorCol:]...)...)
-
-		for j := m.cursorCol + 1; j < len(sheet.Rows[i]); j++ {
-			sheet.Rows[i][j].Col = j
-		}
+	if !m.canReshape() {
+		return
 	}
-	sheet.MaxCols++
-	m.modified = true
+
+	sheet := &m.sheets[m.currentSheet]
+	step := m.newStep("insert column "+ui.ColIndexToLetter(m.cursorCol), stepInsertCol)
+	step.index = m.cursorCol
+	step.cells = make([]models.Cell, len(sheet.Rows))
+	step.present = make([]bool, len(sheet.Rows))
+	for i, row := range sheet.Rows {
+		step.present[i] = m.cursorCol <= len(row)
+	}
+	insertColumnAt(sheet, step.index, step.cells, step.present)
+	sheet.Sort = nil
 	m.recalculateFormulas()
+	m.applyFilters()
+	m.recordStep(step)
 	m.status = models.StatusMsg{
 		Message: fmt.Sprintf("Column inserted at %s", ui.ColIndexToLetter(m.cursorCol)),
 		Type:    models.StatusSuccess,
 	}
 }
 
-// pasteCell pastes clipboard content to current cell
-func (m *Model) pasteCell() {
-	content, err := clipboard.ReadAll()
-	if err != nil {
-		m.status = models.StatusMsg{Message: "Failed to paste", Type: models.StatusError}
-		return
-	}
-
-	sheet := &m.sheets[m.currentSheet]
-	if m.cursorRow >= len(sheet.Rows) {
-		for i := len(sheet.Rows); i <= m.cursorRow; i++ {
-			sheet.Rows = append(sheet.Rows, make([]models.Cell, sheet.MaxCols))
-		}
-	}
-	if m.cursorCol >= len(sheet.Rows[m.cursorRow]) {
-		oldLen := len(sheet.Rows[m.cursorRow])
-		newRow := make([]models.Cell, sheet.MaxCols)
-		copy(newRow, sheet.Rows[m.cursorRow])
-		for i := oldLen; i < len(newRow); i++ {
-			newRow[i] = models.Cell{Row: m.cursorRow, Col: i}
+// removeRow deletes a row from a sheet and returns its cells
+func removeRow(sheet *models.Sheet, row int) []models.Cell {
+	cells := sheet.Rows[row]
+	sheet.Rows = append(sheet.Rows[:row:row], sheet.Rows[row+1:]...)
+	sheet.MaxRows = len(sheet.Rows)
+	shiftRowLayout(sheet, row, -1)
+	for i := row; i < len(sheet.Rows); i++ {
+		for j := range sheet.Rows[i] {
+			sheet.Rows[i][j].Row = i
 		}
-		sheet.Rows[m.cursorRow] = newRow
 	}
+	return cells
+}
 
-	lines := strings.Split(content, "\n")
-	for rowOffset, line := range lines {
-		targetRow := m.cursorRow + rowOffset
-		if targetRow >= sheet.MaxRows {
-			break
+// insertRowAt inserts cells as a new row of a sheet
+func insertRowAt(sheet *models.Sheet, row int, cells []models.Cell) {
+	inserted := append([]models.Cell(nil), cells...)
+	sheet.Rows = append(sheet.Rows[:row:row], append([][]models.Cell{inserted}, sheet.Rows[row:]...)...)
+	sheet.MaxRows = len(sheet.Rows)
+	shiftRowLayout(sheet, row, 1)
+	for i := row; i < len(sheet.Rows); i++ {
+		for j := range sheet.Rows[i] {
+			sheet.Rows[i][j].Row = i
+			sheet.Rows[i][j].Col = j
 		}
+	}
+}
 
-		cells := strings.Split(line, "\t")
-		for colOffset, cellValue := range cells {
-			targetCol := m.cursorCol + colOffset
-			if targetCol >= sheet.MaxCols {
-				break
-			}
+// shiftRowLayout moves the explicit heights, hidden state, outline levels
+// and filter header of the rows from row on by delta after a row is inserted
+// or deleted
+func shiftRowLayout(sheet *models.Sheet, row, delta int) {
+	if sheet.FilterHeader > row || delta > 0 && sheet.FilterHeader == row {
+		sheet.FilterHeader += delta
+	}
+	sheet.RowHeights = shiftKeys(sheet.RowHeights, row, delta)
+	sheet.HiddenRows = shiftKeys(sheet.HiddenRows, row, delta)
+	sheet.RowLevels = shiftKeys(sheet.RowLevels, row, delta)
+	sheet.Validations = shiftValidations(sheet.Validations, row, delta, false)
+}
 
-			if targetRow >= len(sheet.Rows) {
-				continue
-			}
-			if targetCol >= len(sheet.Rows[targetRow]) {
-				continue
-			}
+// shiftColLayout moves the widths, hidden state, outline levels and filters
+// of the columns from col on by delta after a column is inserted or deleted
+func shiftColLayout(sheet *models.Sheet, col, delta int) {
+	sheet.Filters = shiftFilters(sheet.Filters, col, delta)
+	sheet.ColWidths = shiftKeys(sheet.ColWidths, col, delta)
+	sheet.HiddenCols = shiftKeys(sheet.HiddenCols, col, delta)
+	sheet.ColLevels = shiftKeys(sheet.ColLevels, col, delta)
+	sheet.Validations = shiftValidations(sheet.Validations, col, delta, true)
+}
 
-			cell := &sheet.Rows[targetRow][targetCol]
-			cellValue = strings.TrimSpace(cellValue)
+// shiftKeys moves the entries of a map keyed by row or column from index at
+// on by delta; when delta is negative the entry at index at is dropped
+func shiftKeys[V any](entries map[int]V, at, delta int) map[int]V {
+	if len(entries) == 0 {
+		return entries
+	}
+	shifted := make(map[int]V, len(entries))
+	for i, v := range entries {
+		switch {
+		case i < at:
+			shifted[i] = v
+		case delta < 0 && i == at:
+		default:
+			shifted[i+delta] = v
+		}
+	}
+	return shifted
+}
 
-			if strings.HasPrefix(cellValue, "=") {
-				cell.Formula = cellValue[1:]
-				cell.Value = m.evaluateFormula(cell.Formula)
-			} else {
-				cell.Value = cellValue
-				cell.Formula = ""
+// removeColumn deletes a column from a sheet and returns its cells, with
+// whether each row held one
+func removeColumn(sheet *models.Sheet, col int) ([]models.Cell, []bool) {
+	cells := make([]models.Cell, len(sheet.Rows))
+	present := make([]bool, len(sheet.Rows))
+	for i, row := range sheet.Rows {
+		if col < len(row) {
+			cells[i], present[i] = row[col], true
+			sheet.Rows[i] = append(row[:col:col], row[col+1:]...)
+			for j := col; j < len(sheet.Rows[i]); j++ {
+				sheet.Rows[i][j].Col = j
 			}
 		}
 	}
+	sheet.MaxCols--
+	shiftColLayout(sheet, col, -1)
+	return cells, present
+}
 
-	m.modified = true
-	m.recalculateFormulas()
-	m.status = models.StatusMsg{Message: "Pasted", Type: models.StatusSuccess}
+// insertColumnAt inserts a column of cells into the rows marked present
+func insertColumnAt(sheet *models.Sheet, col int, cells []models.Cell, present []bool) {
+	for i := range sheet.Rows {
+		if i >= len(present) || !present[i] || col > len(sheet.Rows[i]) {
+			continue
+		}
+		row := sheet.Rows[i]
+		sheet.Rows[i] = append(row[:col:col], append([]models.Cell{cells[i]}, row[col:]...)...)
+		for j := col; j < len(sheet.Rows[i]); j++ {
+			sheet.Rows[i][j].Row = i
+			sheet.Rows[i][j].Col = j
+		}
+	}
+	sheet.MaxCols++
+	shiftColLayout(sheet, col, 1)
+}
+
+// pasteCell puts the chosen register, or the clipboard, at the cursor or
+// across the selection
+func (m *Model) pasteCell() {
+	m.put()
 }
 
 // saveFile saves the current workbook
 func (m *Model) saveFile() {
-	if m.fileFormat == "csv" {
-		err := loader.SaveCSV(m.sheets[m.currentSheet], m.filename)
-		if err != nil {
-			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Save failed: %v", err),
-				Type:    models.StatusError,
-			}
-			return
+	// Data read from stdin has no file yet, so ask for one
+	if m.filename == "" {
+		m.openSaveAs()
+		return
+	}
+
+	// Lazily loaded sheets are read in full unless their edits are written
+	// back to the database they came from
+	if m.fileFormat != loader.FormatSQLite && !m.loadAllRows(m.allSheetIndexes()...) {
+		return
+	}
+
+	sheets := m.fileSheets()
+	var err error
+	switch m.fileFormat {
+	case loader.FormatCSV:
+		err = loader.SaveCSV(m.fileSheet(), m.filename)
+	case loader.FormatSQLite:
+		err = loader.SaveSQLite(sheets, m.filename)
+	case loader.FormatTSV:
+		err = loader.Export(m.fileSheet(), m.filename)
+	case loader.FormatJSON:
+		if len(sheets) > 1 {
+			err = loader.ExportWorkbookJSON(sheets, m.filename)
+		} else {
+			err = loader.Export(m.fileSheet(), m.filename)
 		}
-	} else {
-		err := loader.SaveExcel(m.sheets, m.filename)
-		if err != nil {
-			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Save failed: %v", err),
-				Type:    models.StatusError,
-			}
-			return
+	default:
+		err = loader.SaveExcel(sheets, m.filename)
+	}
+	if err != nil {
+		m.status = models.StatusMsg{
+			Message: fmt.Sprintf("Save failed: %v", err),
+			Type:    models.StatusError,
 		}
+		return
 	}
 
-	m.modified = false
+	m.markSaved()
 	m.quitConfirm = false
 	m.status = models.StatusMsg{
 		Message: fmt.Sprintf("✓ Saved to %s", m.filename),
@@ -329,6 +480,34 @@ func (m *Model) saveFile() {
 	}
 }
 
+// fileSheets returns the sheets that are saved with the file, leaving out
+// query results
+func (m Model) fileSheets() []models.Sheet {
+	sheets := make([]models.Sheet, 0, len(m.sheets))
+	for _, sheet := range m.sheets {
+		if sheet.Query == "" {
+			sheets = append(sheets, sheet)
+		}
+	}
+	return sheets
+}
+
+// fileSheet returns the sheet saved by single-sheet formats: the current
+// sheet, or the first sheet of the file while a query result is shown
+func (m Model) fileSheet() models.Sheet {
+	if m.sheets[m.currentSheet].Query == "" {
+		return m.sheets[m.currentSheet]
+	}
+	return m.fileSheets()[0]
+}
+
+// openSaveAs opens the Save As prompt, prefilled with the current file name
+func (m *Model) openSaveAs() {
+	m.mode = models.ModeSaveAs
+	m.saveAsInput.Focus()
+	m.saveAsInput.SetValue(m.filename)
+}
+
 // updateSaveAs handles save as mode
 func (m Model) updateSaveAs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
@@ -346,12 +525,7 @@ func (m Model) updateSaveAs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			oldFormat := m.fileFormat
 			m.filename = filename
 
-			ext := strings.ToLower(strings.TrimPrefix(filename[strings.LastIndex(filename, "."):], "."))
-			if ext == "csv" {
-				m.fileFormat = "csv"
-			} else {
-				m.fileFormat = "xlsx"
-			}
+			m.fileFormat = loader.FileFormat(filename)
 
 			m.saveFile()
 			if m.status.Type == models.StatusError {
@@ -368,80 +542,69 @@ func (m Model) updateSaveAs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-// fillDown fills current cell value down to selected range
+// fillDown copies the top row of the selection down through the rest of it
 func (m *Model) fillDown() {
-	if !m.isSelecting {
-		m.status = models.StatusMsg{Message: "Select range first (V)", Type: models.StatusWarning}
-		return
-	}
-
-	sheet := &m.sheets[m.currentSheet]
-	startRow := m.selectStart[0]
-	endRow := m.selectEnd[0]
-	col := m.selectStart[1]
+	m.fillSelection(true)
+}
 
-	if startRow > endRow {
-		startRow, endRow = endRow, startRow
-	}
+// fillRight copies the left column of the selection across the rest of it
+func (m *Model) fillRight() {
+	m.fillSelection(false)
+}
 
-	if startRow >= len(sheet.Rows) || col >= len(sheet.Rows[startRow]) {
+// fillSelection fills the selection from its top row (down) or left column
+func (m *Model) fillSelection(down bool) {
+	if !m.canEdit() {
 		return
 	}
-
-	sourceCell := sheet.Rows[startRow][col]
-	for row := startRow + 1; row <= endRow && row < len(sheet.Rows); row++ {
-		if col < len(sheet.Rows[row]) {
-			cell := &sheet.Rows[row][col]
-			cell.Value = sourceCell.Value
-			cell.Formula = sourceCell.Formula
-		}
-	}
-
-	m.modified = true
-	m.recalculateFormulas()
-	m.status = models.StatusMsg{
-		Message: fmt.Sprintf("Filled %d cells", endRow-startRow),
-		Type:    models.StatusSuccess,
-	}
-}
-
-// fillRight fills current cell value right to selected range
-func (m *Model) fillRight() {
 	if !m.isSelecting {
 		m.status = models.StatusMsg{Message: "Select range first (V)", Type: models.StatusWarning}
 		return
 	}
 
 	sheet := &m.sheets[m.currentSheet]
-	row := m.selectStart[0]
-	startCol := m.selectStart[1]
-	endCol := m.selectEnd[1]
-
-	if startCol > endCol {
-		startCol, endCol = endCol, startCol
-	}
-
-	if row >= len(sheet.Rows) || startCol >= len(sheet.Rows[row]) {
+	startRow, startCol, endRow, endCol := m.selectionBounds()
+	endRow = ui.Min(endRow, sheet.MaxRows-1)
+	endCol = ui.Min(endCol, sheet.MaxCols-1)
+
+	label := "fill right"
+	firstRow, firstCol := startRow, startCol+1
+	if down {
+		label = "fill down"
+		firstRow, firstCol = startRow+1, startCol
+	}
+	if firstRow > endRow || firstCol > endCol {
+		m.status = models.StatusMsg{Message: "Selection has nothing to fill", Type: models.StatusWarning}
 		return
 	}
 
-	sourceCell := sheet.Rows[row][startCol]
-	for col := startCol + 1; col <= endCol && col < len(sheet.Rows[row]); col++ {
-		cell := &sheet.Rows[row][col]
-		cell.Value = sourceCell.Value
-		cell.Formula = sourceCell.Formula
+	step := m.newStep(label, stepCells)
+	step.snapshot(sheet, firstRow, firstCol, endRow, endCol)
+	for row := firstRow; row <= endRow; row++ {
+		for col := firstCol; col <= endCol; col++ {
+			source := cellOrEmpty(sheet, row, startCol)
+			if down {
+				source = cellOrEmpty(sheet, startRow, col)
+			}
+			cell := ensureCell(sheet, row, col)
+			cell.Value = source.Value
+			cell.Formula = source.Formula
+		}
 	}
 
-	m.modified = true
 	m.recalculateFormulas()
+	m.recordStep(step)
 	m.status = models.StatusMsg{
-		Message: fmt.Sprintf("Filled %d cells", endCol-startCol),
+		Message: fmt.Sprintf("Filled %d cells", (endRow-firstRow+1)*(endCol-firstCol+1)),
 		Type:    models.StatusSuccess,
 	}
 }
 
 // applyFormulaToRange applies current cell's formula to entire selected range
 func (m *Model) applyFormulaToRange() {
+	if !m.canEdit() {
+		return
+	}
 	if !m.isSelecting {
 		m.status = models.StatusMsg{Message: "Select range first (V)", Type: models.StatusWarning}
 		return
@@ -479,6 +642,8 @@ func (m *Model) applyFormulaToRange() {
 	sourceCol := m.cursorCol
 
 	cellsUpdated := 0
+	step := m.newStep("apply formula", stepCells)
+	step.snapshot(sheet, startRow, startCol, ui.Min(endRow, len(sheet.Rows)-1), endCol)
 
 	// Apply formula with relative references
 	for row := startRow; row <= endRow && row < len(sheet.Rows); row++ {
@@ -501,15 +666,16 @@ func (m *Model) applyFormulaToRange() {
 		}
 	}
 
-	m.modified = true
 	m.recalculateFormulas()
+	m.recordStep(step)
 	m.status = models.StatusMsg{
 		Message: fmt.Sprintf("Applied formula to %d cells", cellsUpdated),
 		Type:    models.StatusSuccess,
 	}
 }
 
-// adjustFormulaReferences adjusts cell references in formula by given offset
+// adjustFormulaReferences adjusts cell references in formula by given offset.
+// Parts of a reference anchored with $ ($A1, A$1, $A$1) are left unchanged.
 func (m *Model) adjustFormulaReferences(formula string, rowOffset, colOffset int) string {
 	// This is a simplified version - it handles basic cell references
 	// For production, you'd want a proper formula parser
@@ -517,49 +683,72 @@ func (m *Model) adjustFormulaReferences(formula string, rowOffset, colOffset int
 	result := strings.ToUpper(formula)
 	var adjusted strings.Builder
 
+	isLetter := func(c byte) bool { return c >= 'A' && c <= 'Z' }
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+
 	i := 0
 	for i < len(result) {
-		if result[i] >= 'A' && result[i] <= 'Z' {
+		refStart := i
+		colAbs := result[i] == '$' && i+1 < len(result) && isLetter(result[i+1])
+		if colAbs {
+			i++
+		}
+
+		if isLetter(result[i]) {
 			// Found potential cell reference
-			colStart := i
 			col := 0
 
 			// Parse column letters
-			for i < len(result) && result[i] >= 'A' && result[i] <= 'Z' {
+			for i < len(result) && isLetter(result[i]) {
 				col = col*26 + int(result[i]-'A') + 1
 				i++
 			}
 			col--
 
+			rowAbs := i+1 < len(result) && result[i] == '$' && isDigit(result[i+1])
+			if rowAbs {
+				i++
+			}
+
 			// Check if followed by number (row)
-			if i < len(result) && result[i] >= '0' && result[i] <= '9' {
-				// rowStart := i
+			if i < len(result) && isDigit(result[i]) {
 				row := 0
 
 				// Parse row number
-				for i < len(result) && result[i] >= '0' && result[i] <= '9' {
+				for i < len(result) && isDigit(result[i]) {
 					row = row*10 + int(result[i]-'0')
 					i++
 				}
 				row--
 
 				// Adjust the reference
-				newRow := row + rowOffset
-				newCol := col + colOffset
+				newRow, newCol := row, col
+				if !rowAbs {
+					newRow += rowOffset
+				}
+				if !colAbs {
+					newCol += colOffset
+				}
 
 				if newRow >= 0 && newCol >= 0 {
+					if colAbs {
+						adjusted.WriteByte('$')
+					}
 					adjusted.WriteString(ui.ColIndexToLetter(newCol))
+					if rowAbs {
+						adjusted.WriteByte('$')
+					}
 					adjusted.WriteString(strconv.Itoa(newRow + 1))
 				} else {
 					// Invalid reference, keep original
-					adjusted.WriteString(result[colStart:i])
+					adjusted.WriteString(result[refStart:i])
 				}
 			} else {
 				// Not a cell reference, keep original
-				adjusted.WriteString(result[colStart:i])
+				adjusted.WriteString(result[refStart:i])
 			}
 		} else {
-			adjusted.WriteByte(result[i])
+			adjusted.WriteString(result[refStart : i+1])
 			i++
 		}
 	}
@@ -569,6 +758,9 @@ func (m *Model) adjustFormulaReferences(formula string, rowOffset, colOffset int
 
 // renderEditMode renders the edit mode overlay
 func (m Model) renderEditMode() string {
+	if len(m.pickChoices) > 0 {
+		return ui.RenderModal(m.width, m.height, m.renderPicker())
+	}
 	base := m.renderNormal()
 
 	t := theme.GetCurrentTheme()
@@ -608,7 +800,7 @@ func (m Model) renderSaveAs() string {
 	content += m.saveAsInput.View() + "\n\n"
 	content += lipgloss.NewStyle().
 		Foreground(t.DimText).
-		Render("Supported formats: .xlsx, .csv")
+		Render("Supported formats: .xlsx, .csv, .tsv, .json, .db, .sqlite")
 
 	return m.styles.Modal.Width(50).Render(content)
 }
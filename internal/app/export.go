@@ -0,0 +1,119 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/loader"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// Export scopes offered by the export modal
+const (
+	exportScopeSheet = iota
+	exportScopeSelection
+	exportScopeVisible
+	exportScopeWorkbook
+	exportScopeCount
+)
+
+// exportScopeNames are the labels shown in the export modal, indexed by scope
+var exportScopeNames = []string{"Whole sheet", "Current selection", "Visible rows", "All sheets"}
+
+// nextExportScope cycles the export scope by step, skipping the selection
+// scope when nothing is selected
+func (m Model) nextExportScope(step int) int {
+	scope := m.exportScope
+	for i := 0; i < exportScopeCount; i++ {
+		scope = (scope + step + exportScopeCount) % exportScopeCount
+		if scope != exportScopeSelection || m.isSelecting {
+			return scope
+		}
+	}
+	return exportScopeSheet
+}
+
+// scopedSheet returns the part of the current sheet covered by the export scope
+func (m Model) scopedSheet(scope int) models.Sheet {
+	sheet := m.sheets[m.currentSheet]
+
+	switch scope {
+	case exportScopeSelection:
+		if !m.isSelecting {
+			return sheet
+		}
+		startRow, startCol, endRow, endCol := m.selectionBounds()
+		rows := make([]int, 0, endRow-startRow+1)
+		for row := startRow; row <= endRow; row++ {
+			rows = append(rows, row)
+		}
+		return loader.SubSheet(sheet, rows, startCol, endCol)
+
+	case exportScopeVisible:
+		return loader.SubSheet(sheet, m.visibleRowIndices(), 0, sheet.MaxCols-1)
+	}
+
+	return sheet
+}
+
+// visibleRowIndices returns the indices of the rows currently shown in the grid
+func (m Model) visibleRowIndices() []int {
+	rows := make([]int, m.shownRows())
+	for pos := range rows {
+		rows[pos] = m.rowAt(pos)
+	}
+	return rows
+}
+
+// exportSheet exports the current sheet, limited to the chosen scope, to a file
+func (m *Model) exportSheet(filename string) {
+	if m.exportScope == exportScopeWorkbook {
+		m.exportWorkbook(filename)
+		return
+	}
+
+	if !loader.CanExport(filename) {
+		m.status = models.StatusMsg{
+			Message: "Use one of: " + strings.Join(loader.ExportFormats(), " "),
+			Type:    models.StatusError,
+		}
+		return
+	}
+
+	if !m.loadAllRows(m.currentSheet) {
+		return
+	}
+	sheet := m.scopedSheet(m.exportScope)
+	if err := loader.Export(sheet, filename); err != nil {
+		m.status = models.StatusMsg{
+			Message: fmt.Sprintf("Export failed: %v", err),
+			Type:    models.StatusError,
+		}
+	} else {
+		m.status = models.StatusMsg{
+			Message: fmt.Sprintf("✓ Exported %d rows to %s", len(sheet.Rows), filename),
+			Type:    models.StatusSuccess,
+		}
+	}
+}
+
+// exportWorkbook exports every sheet to a directory of CSV files or, for a
+// .json or SQLite target, to a single document keyed by sheet name
+func (m *Model) exportWorkbook(target string) {
+	if !m.loadAllRows(m.allSheetIndexes()...) {
+		return
+	}
+	written, err := loader.ExportWorkbook(m.sheets, target)
+	if err != nil {
+		m.status = models.StatusMsg{
+			Message: fmt.Sprintf("Export failed: %v", err),
+			Type:    models.StatusError,
+		}
+		return
+	}
+
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("✓ Exported %d sheets to %s (%d files)", len(m.sheets), target, len(written)),
+		Type:    models.StatusSuccess,
+	}
+}
@@ -0,0 +1,570 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// filterOps lists the filter operations in the order the filter dialog cycles
+// through them
+var filterOps = []models.FilterOp{
+	models.FilterValues,
+	models.FilterEquals,
+	models.FilterNotEquals,
+	models.FilterContains,
+	models.FilterRegex,
+	models.FilterGreater,
+	models.FilterGreaterEq,
+	models.FilterLess,
+	models.FilterLessEq,
+}
+
+// filterOpNames are the symbols used to show and type each operation
+var filterOpNames = map[models.FilterOp]string{
+	models.FilterEquals:    "=",
+	models.FilterNotEquals: "!=",
+	models.FilterContains:  "contains",
+	models.FilterRegex:     "~",
+	models.FilterGreater:   ">",
+	models.FilterGreaterEq: ">=",
+	models.FilterLess:      "<",
+	models.FilterLessEq:    "<=",
+	models.FilterValues:    "in",
+}
+
+// maxFilterValues caps the pick-list of distinct values in the filter dialog.
+// Values past the cap are not listed and stay shown.
+const maxFilterValues = 500
+
+// filterMatcher tests the cells of one column
+type filterMatcher struct {
+	col   int
+	match func(value string) bool
+}
+
+// compileFilter builds the matcher for a filter. Text comparisons ignore case;
+// ordering comparisons use the same number, date and text ranking as sorting
+// and never match empty cells.
+func compileFilter(f models.Filter) (filterMatcher, error) {
+	want := strings.ToLower(strings.TrimSpace(f.Value))
+	wantValue := parseSortValue(f.Value)
+
+	var match func(string) bool
+	switch f.Op {
+	case models.FilterEquals, models.FilterNotEquals:
+		negate := f.Op == models.FilterNotEquals
+		match = func(v string) bool {
+			got := parseSortValue(v)
+			equal := strings.ToLower(strings.TrimSpace(v)) == want ||
+				(got.kind == wantValue.kind && got.kind != sortText && got.num == wantValue.num)
+			return equal != negate
+		}
+	case models.FilterContains:
+		match = func(v string) bool {
+			return strings.Contains(strings.ToLower(v), want)
+		}
+	case models.FilterRegex:
+		re, err := regexp.Compile(f.Value)
+		if err != nil {
+			return filterMatcher{}, fmt.Errorf("invalid regex: %w", err)
+		}
+		match = re.MatchString
+	case models.FilterGreater, models.FilterGreaterEq, models.FilterLess, models.FilterLessEq:
+		op := f.Op
+		match = func(v string) bool {
+			got := parseSortValue(v)
+			if got.kind == sortEmpty {
+				return false
+			}
+			c := compareSortValues(got, wantValue)
+			switch op {
+			case models.FilterGreater:
+				return c > 0
+			case models.FilterGreaterEq:
+				return c >= 0
+			case models.FilterLess:
+				return c < 0
+			default:
+				return c <= 0
+			}
+		}
+	case models.FilterValues:
+		set := make(map[string]bool, len(f.Values))
+		for _, v := range f.Values {
+			set[strings.ToLower(strings.TrimSpace(v))] = true
+		}
+		exclude := f.Exclude
+		match = func(v string) bool {
+			return set[strings.ToLower(strings.TrimSpace(v))] != exclude
+		}
+	default:
+		return filterMatcher{}, fmt.Errorf("unknown filter")
+	}
+
+	return filterMatcher{col: f.Col, match: match}, nil
+}
+
+// filterString describes a filter, e.g. "B > 100" or "C in East, West"
+func filterString(f models.Filter) string {
+	col := ui.ColIndexToLetter(f.Col)
+	if f.Op == models.FilterValues {
+		values := make([]string, len(f.Values))
+		for i, v := range f.Values {
+			values[i] = v
+			if v == "" {
+				values[i] = "(blank)"
+			}
+		}
+		op := "in"
+		if f.Exclude {
+			op = "not in"
+		}
+		return fmt.Sprintf("%s %s %s", col, op, ui.Truncate(strings.Join(values, ", "), 40))
+	}
+	return fmt.Sprintf("%s %s %s", col, filterOpNames[f.Op], f.Value)
+}
+
+// parseFilter parses a :filter argument such as "B > 100", "C ~ ^A" or
+// "D in East|West"
+func parseFilter(spec string) (models.Filter, error) {
+	colText, rest, _ := strings.Cut(strings.TrimSpace(spec), " ")
+	col, err := ui.ParseColumn(colText)
+	if err != nil {
+		return models.Filter{}, err
+	}
+
+	opText, value, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	value = strings.TrimSpace(value)
+	if opText == "==" {
+		opText = "="
+	} else if opText == "<>" {
+		opText = "!="
+	}
+	for op, name := range filterOpNames {
+		if strings.EqualFold(name, opText) {
+			f := models.Filter{Col: col, Op: op, Value: value}
+			if op == models.FilterValues {
+				f.Value = ""
+				f.Values = strings.Split(value, "|")
+			}
+			_, err := compileFilter(f)
+			return f, err
+		}
+	}
+	return models.Filter{}, fmt.Errorf("unknown operator %q", opText)
+}
+
+// applyFilters recomputes which rows of the current sheet are shown
+func (m *Model) applyFilters() {
+	m.rowView = nil
+	if len(m.sheets) == 0 {
+		return
+	}
+	m.rowView = m.sheetRowView(m.currentSheet)
+	m.snapCursorRow()
+}
+
+// sheetRowView returns the rows of a sheet that are shown, or nil when it
+// has no filters or hidden rows. Rows at or above the filter header pass the
+// filters; hidden rows are never shown.
+func (m *Model) sheetRowView(idx int) []int {
+	sheet := &m.sheets[idx]
+	if len(sheet.Filters) == 0 && len(sheet.HiddenRows) == 0 {
+		return nil
+	}
+
+	matchers := make([]filterMatcher, 0, len(sheet.Filters))
+	if len(sheet.Filters) > 0 {
+		if !m.loadAllRows(idx) {
+			return nil
+		}
+		for _, f := range sheet.Filters {
+			matcher, err := compileFilter(f)
+			if err != nil {
+				m.status = models.StatusMsg{Message: fmt.Sprintf("Filter %s: %v", filterString(f), err), Type: models.StatusError}
+				continue
+			}
+			matchers = append(matchers, matcher)
+		}
+	}
+
+	view := make([]int, 0, sheet.MaxRows)
+	for row := 0; row < sheet.MaxRows; row++ {
+		if sheet.HiddenRows[row] {
+			continue
+		}
+		if row <= sheet.FilterHeader || rowMatches(sheet, row, matchers) {
+			view = append(view, row)
+		}
+	}
+	return view
+}
+
+// rowMatches reports whether a row passes every matcher
+func rowMatches(sheet *models.Sheet, row int, matchers []filterMatcher) bool {
+	for _, matcher := range matchers {
+		value := ""
+		if row < len(sheet.Rows) && matcher.col < len(sheet.Rows[row]) {
+			value = sheet.Rows[row][matcher.col].Value
+		}
+		if !matcher.match(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// shownRows returns the number of rows shown in the grid
+func (m Model) shownRows() int {
+	if m.rowView == nil {
+		return m.sheets[m.currentSheet].MaxRows
+	}
+	return len(m.rowView)
+}
+
+// rowAt returns the sheet row shown at position pos of the grid
+func (m Model) rowAt(pos int) int {
+	if m.rowView == nil {
+		return pos
+	}
+	return m.rowView[pos]
+}
+
+// rowPosition returns the grid position of row, or of the next shown row if
+// row is hidden
+func (m Model) rowPosition(row int) int {
+	if m.rowView == nil {
+		return row
+	}
+	return sort.SearchInts(m.rowView, row)
+}
+
+// moveCursorRows moves the cursor by delta shown rows, staying in the sheet
+func (m *Model) moveCursorRows(delta int) {
+	shown := m.shownRows()
+	if shown == 0 {
+		return
+	}
+	pos := ui.Max(0, ui.Min(m.rowPosition(m.cursorRow)+delta, shown-1))
+	m.cursorRow = m.rowAt(pos)
+	m.adjustViewport()
+}
+
+// snapCursorRow moves the cursor off a hidden row onto the nearest shown row
+// below it, or above it at the end of the sheet
+func (m *Model) snapCursorRow() {
+	shown := m.shownRows()
+	if m.rowView == nil || shown == 0 {
+		return
+	}
+	m.cursorRow = m.rowAt(ui.Min(m.rowPosition(m.cursorRow), shown-1))
+}
+
+// rowShown reports whether row is in a row view; a nil view shows every row
+func rowShown(view []int, row int) bool {
+	if view == nil {
+		return true
+	}
+	pos := sort.SearchInts(view, row)
+	return pos < len(view) && view[pos] == row
+}
+
+// isFiltered reports whether a column of the current sheet has a filter
+func (m Model) isFiltered(col int) bool {
+	for _, f := range m.sheets[m.currentSheet].Filters {
+		if f.Col == col {
+			return true
+		}
+	}
+	return false
+}
+
+// setColumnFilter replaces the filters of a column, or clears them when f is
+// nil, and reapplies filtering
+func (m *Model) setColumnFilter(col int, f *models.Filter) {
+	sheet := &m.sheets[m.currentSheet]
+	filters := make([]models.Filter, 0, len(sheet.Filters)+1)
+	for _, existing := range sheet.Filters {
+		if existing.Col != col {
+			filters = append(filters, existing)
+		}
+	}
+	if f != nil {
+		filters = append(filters, *f)
+	}
+	sheet.Filters = filters
+	m.applyFilters()
+	m.adjustViewport()
+	m.filterStatus()
+}
+
+// shiftFilters moves the filters of the columns from col on by delta after a
+// column is inserted or deleted; the filter of a deleted column is dropped
+func shiftFilters(filters []models.Filter, col, delta int) []models.Filter {
+	if len(filters) == 0 {
+		return filters
+	}
+	shifted := make([]models.Filter, 0, len(filters))
+	for _, f := range filters {
+		switch {
+		case f.Col < col:
+		case delta < 0 && f.Col == col:
+			continue
+		default:
+			f.Col += delta
+		}
+		shifted = append(shifted, f)
+	}
+	return shifted
+}
+
+// clearFilters removes every filter of the current sheet
+func (m *Model) clearFilters() {
+	m.sheets[m.currentSheet].Filters = nil
+	m.applyFilters()
+	m.adjustViewport()
+	m.status = models.StatusMsg{Message: "Filters cleared", Type: models.StatusInfo}
+}
+
+// filterStatus reports how many rows the filters leave
+func (m *Model) filterStatus() {
+	sheet := m.sheets[m.currentSheet]
+	if len(sheet.Filters) == 0 {
+		m.status = models.StatusMsg{Message: "Filter cleared", Type: models.StatusInfo}
+		return
+	}
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Showing %s of %s rows", formatCount(m.shownRows()), formatCount(sheet.MaxRows)),
+		Type:    models.StatusSuccess,
+	}
+}
+
+// formatCount formats a count with thousands separators
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	s := fmt.Sprintf("%d", n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// openFilter opens the filter dialog for the cursor column, starting from its
+// current filter
+func (m *Model) openFilter() {
+	if !m.loadAllRows(m.currentSheet) {
+		return
+	}
+	sheet := m.sheets[m.currentSheet]
+	col := m.cursorCol
+
+	// Distinct values below the header, ordered like an ascending sort
+	seen := make(map[string]bool)
+	var values []string
+	m.filterMore = false
+	for row := sheet.FilterHeader + 1; row < len(sheet.Rows); row++ {
+		value := ""
+		if col < len(sheet.Rows[row]) {
+			value = strings.TrimSpace(sheet.Rows[row][col].Value)
+		}
+		if !seen[strings.ToLower(value)] {
+			if len(values) == maxFilterValues {
+				m.filterMore = true
+				break
+			}
+			seen[strings.ToLower(value)] = true
+			values = append(values, value)
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		a, b := parseSortValue(values[i]), parseSortValue(values[j])
+		return compareSortValues(a, b) < 0
+	})
+
+	m.filterValues = values
+	m.filterChecked = make(map[string]bool, len(values))
+	m.filterCursor = 0
+	m.filterOp = models.FilterValues
+	m.filterInput.SetValue("")
+
+	current := false
+	for _, f := range sheet.Filters {
+		if f.Col != col {
+			continue
+		}
+		current = true
+		m.filterOp = f.Op
+		if f.Op == models.FilterValues {
+			if f.Exclude {
+				for _, v := range values {
+					m.filterChecked[strings.ToLower(v)] = true
+				}
+			}
+			for _, v := range f.Values {
+				m.filterChecked[strings.ToLower(strings.TrimSpace(v))] = !f.Exclude
+			}
+		} else {
+			m.filterInput.SetValue(f.Value)
+		}
+		break
+	}
+	if !current {
+		for _, v := range values {
+			m.filterChecked[strings.ToLower(v)] = true
+		}
+	}
+
+	m.mode = models.ModeFilter
+	m.focusFilterInput()
+}
+
+// focusFilterInput focuses the value input unless the pick-list is shown
+func (m *Model) focusFilterInput() {
+	if m.filterOp == models.FilterValues {
+		m.filterInput.Blur()
+	} else {
+		m.filterInput.Focus()
+	}
+}
+
+// cycleFilterOp moves to the next (step 1) or previous (step -1) operation
+func (m *Model) cycleFilterOp(step int) {
+	idx := 0
+	for i, op := range filterOps {
+		if op == m.filterOp {
+			idx = i
+		}
+	}
+	m.filterOp = filterOps[(idx+step+len(filterOps))%len(filterOps)]
+	m.focusFilterInput()
+}
+
+// updateFilter handles the filter dialog
+func (m Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "esc":
+		m.mode = models.ModeNormal
+		m.filterInput.Blur()
+		return m, nil
+	case "tab":
+		m.cycleFilterOp(1)
+		return m, textinput.Blink
+	case "shift+tab":
+		m.cycleFilterOp(-1)
+		return m, textinput.Blink
+	case "ctrl+x":
+		m.mode = models.ModeNormal
+		m.filterInput.Blur()
+		m.setColumnFilter(m.cursorCol, nil)
+		return m, nil
+	case "enter":
+		m.mode = models.ModeNormal
+		m.filterInput.Blur()
+		m.commitFilter()
+		return m, nil
+	}
+
+	if m.filterOp != models.FilterValues {
+		m.filterInput, cmd = m.filterInput.Update(msg)
+		return m, cmd
+	}
+
+	// Pick-list keys
+	switch msg.String() {
+	case "up", "k":
+		if m.filterCursor > 0 {
+			m.filterCursor--
+		}
+	case "down", "j":
+		if m.filterCursor < len(m.filterValues)-1 {
+			m.filterCursor++
+		}
+	case " ", "x":
+		if m.filterCursor < len(m.filterValues) {
+			key := strings.ToLower(m.filterValues[m.filterCursor])
+			m.filterChecked[key] = !m.filterChecked[key]
+		}
+	case "a":
+		// Select all, or none when everything is already selected
+		all := true
+		for _, v := range m.filterValues {
+			all = all && m.filterChecked[strings.ToLower(v)]
+		}
+		for _, v := range m.filterValues {
+			m.filterChecked[strings.ToLower(v)] = !all
+		}
+	}
+	return m, nil
+}
+
+// commitFilter applies the criterion entered in the filter dialog
+func (m *Model) commitFilter() {
+	f := models.Filter{Col: m.cursorCol, Op: m.filterOp}
+
+	if m.filterOp == models.FilterValues && m.filterMore {
+		// Not every value is listed, so hide the unchecked ones rather than
+		// show the checked ones, keeping what an earlier filter hid
+		f.Exclude = true
+		f.Values = m.uncheckedFilterValues()
+		if len(f.Values) == 0 {
+			m.setColumnFilter(m.cursorCol, nil)
+			return
+		}
+	} else if m.filterOp == models.FilterValues {
+		all := true
+		for _, v := range m.filterValues {
+			if m.filterChecked[strings.ToLower(v)] {
+				f.Values = append(f.Values, v)
+			} else {
+				all = false
+			}
+		}
+		if all {
+			m.setColumnFilter(m.cursorCol, nil)
+			return
+		}
+	} else {
+		f.Value = strings.TrimSpace(m.filterInput.Value())
+		if _, err := compileFilter(f); err != nil {
+			m.status = models.StatusMsg{Message: err.Error(), Type: models.StatusError}
+			return
+		}
+	}
+
+	m.setColumnFilter(m.cursorCol, &f)
+}
+
+// uncheckedFilterValues returns the values unchecked in the pick-list, with
+// the values the column's exclusion filter hides that are not listed
+func (m Model) uncheckedFilterValues() []string {
+	var values []string
+	listed := make(map[string]bool, len(m.filterValues))
+	for _, v := range m.filterValues {
+		listed[strings.ToLower(v)] = true
+		if !m.filterChecked[strings.ToLower(v)] {
+			values = append(values, v)
+		}
+	}
+	for _, f := range m.sheets[m.currentSheet].Filters {
+		if f.Col != m.cursorCol || f.Op != models.FilterValues || !f.Exclude {
+			continue
+		}
+		for _, v := range f.Values {
+			if !listed[strings.ToLower(strings.TrimSpace(v))] {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
@@ -5,14 +5,21 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/CodeOne45/vex-tui/pkg/models"
 )
 
+// FormulaEngine evaluates formulas against the cells of a sheet
 type FormulaEngine struct {
 	sheet *models.Sheet
 }
 
+// NewFormulaEngine creates a formula engine that reads cell values from sheet
+func NewFormulaEngine(sheet *models.Sheet) *FormulaEngine {
+	return &FormulaEngine{sheet: sheet}
+}
+
 // evaluateFormula evaluates a formula and returns the result
 func (m *Model) evaluateFormula(formula string) string {
 	engine := &FormulaEngine{sheet: &m.sheets[m.currentSheet]}
@@ -71,6 +78,32 @@ func (fe *FormulaEngine) Evaluate(formula string) (string, error) {
 	return result, nil
 }
 
+// EvaluateStrict evaluates a formula like Evaluate, but returns an error for
+// a formula it cannot evaluate, such as a call to an unknown function, where
+// Evaluate gives back the formula's text
+func (fe *FormulaEngine) EvaluateStrict(formula string) (string, error) {
+	result, err := fe.Evaluate(formula)
+	if err != nil {
+		return "", err
+	}
+	expr := strings.ToUpper(strings.TrimSpace(formula))
+	if expr == "" || result != expr || fe.isCellReference(expr) {
+		return result, nil
+	}
+	if _, err := strconv.ParseFloat(expr, 64); err == nil {
+		return result, nil
+	}
+
+	name, _, call := strings.Cut(expr, "(")
+	isName := name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	}) < 0
+	if call && isName {
+		return "", fmt.Errorf("unknown function %s", name)
+	}
+	return "", fmt.Errorf("cannot evaluate %s", strings.TrimSpace(formula))
+}
+
 // evaluateSum evaluates SUM function
 func (fe *FormulaEngine) evaluateSum(formula string) (string, error) {
 	rangeStr := fe.extractFunctionArg(formula, "SUM")
@@ -693,3 +726,12 @@ func (m *Model) recalculateFormulas() {
 		}
 	}
 }
+
+// recalculateSheet recalculates the formulas of sheet idx, which need not be
+// the current sheet
+func (m *Model) recalculateSheet(idx int) {
+	current := m.currentSheet
+	m.currentSheet = idx
+	m.recalculateFormulas()
+	m.currentSheet = current
+}
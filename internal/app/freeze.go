@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// freezePanes keeps the given number of top rows and left columns of the
+// current sheet on screen; zero for both unfreezes
+func (m *Model) freezePanes(rows, cols int) {
+	sheet := &m.sheets[m.currentSheet]
+	label := "freeze panes"
+	if rows == 0 && cols == 0 {
+		label = "unfreeze panes"
+	}
+	step := m.newStep(label, stepLayout)
+	sheet.FreezeRows = ui.Max(0, ui.Min(rows, sheet.MaxRows))
+	sheet.FreezeCols = ui.Max(0, ui.Min(cols, sheet.MaxCols))
+	m.adjustViewport()
+	m.recordStep(step)
+
+	if sheet.FreezeRows == 0 && sheet.FreezeCols == 0 {
+		m.status = models.StatusMsg{Message: "Panes unfrozen", Type: models.StatusInfo}
+		return
+	}
+	var parts []string
+	if sheet.FreezeRows > 0 {
+		parts = append(parts, plural(sheet.FreezeRows, "row"))
+	}
+	if sheet.FreezeCols > 0 {
+		parts = append(parts, plural(sheet.FreezeCols, "column"))
+	}
+	m.status = models.StatusMsg{Message: "Froze " + strings.Join(parts, " and "), Type: models.StatusSuccess}
+}
+
+// toggleFreeze freezes the rows above and columns left of the cursor, like
+// Excel's Freeze Panes, or unfreezes a sheet that already has frozen panes
+func (m *Model) toggleFreeze() {
+	sheet := m.sheets[m.currentSheet]
+	if sheet.FreezeRows > 0 || sheet.FreezeCols > 0 {
+		m.freezePanes(0, 0)
+		return
+	}
+	if m.cursorRow == 0 && m.cursorCol == 0 {
+		m.status = models.StatusMsg{Message: "Move below or right of A1 to freeze the rows above and columns left of the cursor", Type: models.StatusInfo}
+		return
+	}
+	m.freezePanes(m.cursorRow, m.cursorCol)
+}
+
+// parseFreeze reads the arguments of :freeze, either a cell such as B2
+// whose rows above and columns left are frozen, or a row count followed by
+// an optional column count
+func parseFreeze(args string) (rows, cols int, err error) {
+	fields := strings.Fields(args)
+	if len(fields) == 1 {
+		if row, col, err := ui.ParseCellRef(fields[0]); err == nil {
+			return row, col, nil
+		}
+	}
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, 0, fmt.Errorf("expected a cell or a row count")
+	}
+
+	counts := make([]int, 2)
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid count %q", field)
+		}
+		counts[i] = n
+	}
+	return counts[0], counts[1], nil
+}
+
+// plural formats a count with a noun, adding an s when it is not one
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// shownCol returns the first column from col on, stepping by step (1 or -1),
+// that is not hidden, or -1 when there is none
+func (m Model) shownCol(col, step int) int {
+	sheet := m.sheets[m.currentSheet]
+	for ; col >= 0 && col < sheet.MaxCols; col += step {
+		if !sheet.HiddenCols[col] {
+			return col
+		}
+	}
+	return -1
+}
+
+// moveCursorCols moves the cursor by delta shown columns, staying in the
+// sheet
+func (m *Model) moveCursorCols(delta int) {
+	step := 1
+	if delta < 0 {
+		step, delta = -1, -delta
+	}
+	for ; delta > 0; delta-- {
+		col := m.shownCol(m.cursorCol+step, step)
+		if col < 0 {
+			break
+		}
+		m.cursorCol = col
+	}
+	m.adjustViewport()
+}
+
+// snapCursorCol moves the cursor off a hidden column onto the nearest shown
+// column right of it, or left of it at the end of the sheet
+func (m *Model) snapCursorCol() {
+	if !m.sheets[m.currentSheet].HiddenCols[m.cursorCol] {
+		return
+	}
+	if col := m.shownCol(m.cursorCol, 1); col >= 0 {
+		m.cursorCol = col
+	} else if col := m.shownCol(m.cursorCol, -1); col >= 0 {
+		m.cursorCol = col
+	}
+}
+
+// hideRows hides rows startRow..endRow, keeping at least one row shown, and
+// reports whether it did
+func (m *Model) hideRows(startRow, endRow int) bool {
+	sheet := &m.sheets[m.currentSheet]
+	if m.rowPosition(endRow+1)-m.rowPosition(startRow) >= m.shownRows() {
+		m.status = models.StatusMsg{Message: "Cannot hide every row", Type: models.StatusWarning}
+		return false
+	}
+	step := m.newStep("hide "+m.outlineAxis(false).label(startRow, endRow), stepLayout)
+	if sheet.HiddenRows == nil {
+		sheet.HiddenRows = make(map[int]bool)
+	}
+	for row := startRow; row <= endRow; row++ {
+		sheet.HiddenRows[row] = true
+	}
+	m.isSelecting = false
+	m.applyFilters()
+	m.adjustViewport()
+	m.recordStep(step)
+	m.status = models.StatusMsg{Message: "Hid " + plural(endRow-startRow+1, "row"), Type: models.StatusSuccess}
+	return true
+}
+
+// hideCols hides columns startCol..endCol, keeping at least one column
+// shown, and reports whether it did
+func (m *Model) hideCols(startCol, endCol int) bool {
+	sheet := &m.sheets[m.currentSheet]
+	if m.shownCol(0, 1) >= startCol && m.shownCol(endCol+1, 1) < 0 {
+		m.status = models.StatusMsg{Message: "Cannot hide every column", Type: models.StatusWarning}
+		return false
+	}
+	step := m.newStep("hide "+m.outlineAxis(true).label(startCol, endCol), stepLayout)
+	if sheet.HiddenCols == nil {
+		sheet.HiddenCols = make(map[int]bool)
+	}
+	for col := startCol; col <= endCol; col++ {
+		sheet.HiddenCols[col] = true
+	}
+	m.isSelecting = false
+	m.adjustViewport()
+	m.recordStep(step)
+	m.status = models.StatusMsg{Message: "Hid " + plural(endCol-startCol+1, "column"), Type: models.StatusSuccess}
+	return true
+}
+
+// unhideRows shows the hidden rows between startRow and endRow
+func (m *Model) unhideRows(startRow, endRow int) int {
+	step := m.newStep("unhide "+m.outlineAxis(false).label(startRow, endRow), stepLayout)
+	count := m.showRows(startRow, endRow)
+	m.recordStep(step)
+	return count
+}
+
+// unhideCols shows the hidden columns between startCol and endCol
+func (m *Model) unhideCols(startCol, endCol int) int {
+	step := m.newStep("unhide "+m.outlineAxis(true).label(startCol, endCol), stepLayout)
+	count := m.showCols(startCol, endCol)
+	m.recordStep(step)
+	return count
+}
+
+// showRows clears the hidden state of the rows between startRow and endRow
+// and returns how many were hidden
+func (m *Model) showRows(startRow, endRow int) int {
+	sheet := &m.sheets[m.currentSheet]
+	count := 0
+	for row := range sheet.HiddenRows {
+		if row >= startRow && row <= endRow {
+			delete(sheet.HiddenRows, row)
+			count++
+		}
+	}
+	m.applyFilters()
+	m.adjustViewport()
+	return count
+}
+
+// showCols clears the hidden state of the columns between startCol and
+// endCol and returns how many were hidden
+func (m *Model) showCols(startCol, endCol int) int {
+	sheet := &m.sheets[m.currentSheet]
+	count := 0
+	for col := range sheet.HiddenCols {
+		if col >= startCol && col <= endCol {
+			delete(sheet.HiddenCols, col)
+			count++
+		}
+	}
+	m.adjustViewport()
+	return count
+}
+
+// hideCommand runs :hide and :unhide. The target is rows or columns of the
+// selection, or of the cursor; :unhide without a selection shows every
+// hidden row or column, and with no target both.
+func (m *Model) hideCommand(hide bool, target string) {
+	startRow, startCol, endRow, endCol := m.selectedRange()
+	if !hide && !m.isSelecting {
+		sheet := m.sheets[m.currentSheet]
+		startRow, startCol, endRow, endCol = 0, 0, sheet.MaxRows-1, sheet.MaxCols-1
+	}
+
+	switch strings.ToLower(target) {
+	case "row", "rows", "r":
+		if hide {
+			m.hideRows(startRow, endRow)
+			return
+		}
+		m.unhideStatus(m.unhideRows(startRow, endRow), 0)
+	case "col", "cols", "column", "columns", "c":
+		if hide {
+			m.hideCols(startCol, endCol)
+			return
+		}
+		m.unhideStatus(0, m.unhideCols(startCol, endCol))
+	case "", "all":
+		if hide {
+			m.status = models.StatusMsg{Message: "Usage: :hide rows or :hide cols", Type: models.StatusWarning}
+			return
+		}
+		// Rows and columns come back as one step
+		step := m.newStep("unhide", stepLayout)
+		rows, cols := m.showRows(startRow, endRow), m.showCols(startCol, endCol)
+		m.recordStep(step)
+		m.unhideStatus(rows, cols)
+	default:
+		m.status = models.StatusMsg{Message: fmt.Sprintf("Unknown target %q (rows or cols)", target), Type: models.StatusWarning}
+	}
+}
+
+// unhideStatus reports how many rows and columns :unhide showed
+func (m *Model) unhideStatus(rows, cols int) {
+	var parts []string
+	if rows > 0 {
+		parts = append(parts, plural(rows, "row"))
+	}
+	if cols > 0 {
+		parts = append(parts, plural(cols, "column"))
+	}
+	if len(parts) == 0 {
+		m.status = models.StatusMsg{Message: "Nothing hidden", Type: models.StatusInfo}
+		return
+	}
+	m.status = models.StatusMsg{Message: "Unhid " + strings.Join(parts, " and "), Type: models.StatusSuccess}
+}
@@ -23,8 +23,11 @@ type KeyMap struct {
 	Detail       key.Binding
 	Jump         key.Binding
 	ToggleForm   key.Binding
+	ToggleWrap   key.Binding
 	Copy         key.Binding
 	CopyRow      key.Binding
+	Cut          key.Binding
+	Register     key.Binding
 	Export       key.Binding
 	Theme        key.Binding
 	Help         key.Binding
@@ -45,6 +48,23 @@ type KeyMap struct {
 	ApplyFormula key.Binding
 	ColWidthInc  key.Binding
 	ColWidthDec  key.Binding
+	AutoFit      key.Binding
+	NextBuffer   key.Binding
+	PrevBuffer   key.Binding
+	BufferList   key.Binding
+	Command      key.Binding
+	SortAsc      key.Binding
+	SortDesc     key.Binding
+	Filter       key.Binding
+	Replace      key.Binding
+	Undo         key.Binding
+	Redo         key.Binding
+	Freeze       key.Binding
+	Window       key.Binding
+	Collapse     key.Binding
+	Expand       key.Binding
+	OutlineLevel key.Binding
+	Comment      key.Binding
 }
 
 // ShortHelp returns key bindings to be shown in the mini help view
@@ -58,12 +78,17 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 		{k.Up, k.Down, k.Left, k.Right},
 		{k.PageUp, k.PageDown, k.FirstCol, k.LastCol},
 		{k.Home, k.End, k.NextSheet, k.PrevSheet},
-		{k.Edit, k.Delete, k.Copy, k.Paste},
+		{k.Edit, k.Delete, k.Copy, k.Cut, k.Paste, k.Register},
+		{k.Undo, k.Redo, k.Replace},
 		{k.InsertRow, k.InsertCol, k.DeleteRow, k.DeleteCol},
 		{k.FillDown, k.FillRight, k.ApplyFormula, k.ToggleForm},
+		{k.SortAsc, k.SortDesc, k.Filter, k.Freeze},
+		{k.Collapse, k.Expand, k.OutlineLevel},
+		{k.ColWidthInc, k.ColWidthDec, k.AutoFit, k.ToggleWrap},
 		{k.Search, k.NextResult, k.PrevResult, k.ClearSearch},
-		{k.Detail, k.Jump, k.Export, k.Theme},
+		{k.Detail, k.Comment, k.Jump, k.Export, k.Theme},
 		{k.Save, k.SaveAs, k.Visualize, k.SelectRange},
+		{k.NextBuffer, k.PrevBuffer, k.BufferList, k.Window, k.Command},
 		{k.Help, k.Quit},
 	}
 }
@@ -90,8 +115,11 @@ func DefaultKeyMap() KeyMap {
 		Detail:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "detail/edit")),
 		Jump:         key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("^g", "jump")),
 		ToggleForm:   key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "formulas")),
-		Copy:         key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "copy")),
+		ToggleWrap:   key.NewBinding(key.WithKeys("W"), key.WithHelp("W", "wrap text")),
+		Copy:         key.NewBinding(key.WithKeys("c", "y"), key.WithHelp("c/y", "yank")),
 		CopyRow:      key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "copy row")),
+		Cut:          key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "cut")),
+		Register:     key.NewBinding(key.WithKeys("\""), key.WithHelp("\"a", "register")),
 		Export:       key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export")),
 		Theme:        key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "theme")),
 		Help:         key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
@@ -104,7 +132,7 @@ func DefaultKeyMap() KeyMap {
 		DeleteCol:    key.NewBinding(key.WithKeys("d", "c"), key.WithHelp("dc", "delete col")),
 		InsertRow:    key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "insert row")),
 		InsertCol:    key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "insert col")),
-		Paste:        key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "paste")),
+		Paste:        key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "put")),
 		Save:         key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("^s", "save")),
 		SaveAs:       key.NewBinding(key.WithKeys("ctrl+shift+s"), key.WithHelp("^⇧s", "save as")),
 		FillDown:     key.NewBinding(key.WithKeys("ctrl+j"), key.WithHelp("^j", "fill down")),
@@ -112,5 +140,22 @@ func DefaultKeyMap() KeyMap {
 		ApplyFormula: key.NewBinding(key.WithKeys("ctrl+a"), key.WithHelp("^a", "apply formula")),
 		ColWidthInc:  key.NewBinding(key.WithKeys(">"), key.WithHelp(">", "widen col")),
 		ColWidthDec:  key.NewBinding(key.WithKeys("<"), key.WithHelp("<", "narrow col")),
+		AutoFit:      key.NewBinding(key.WithKeys("="), key.WithHelp("=", "auto-fit col")),
+		NextBuffer:   key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "next file")),
+		PrevBuffer:   key.NewBinding(key.WithKeys("["), key.WithHelp("[", "prev file")),
+		BufferList:   key.NewBinding(key.WithKeys("B"), key.WithHelp("B", "files")),
+		Command:      key.NewBinding(key.WithKeys(":"), key.WithHelp(":", "command")),
+		SortAsc:      key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort asc")),
+		SortDesc:     key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "sort desc")),
+		Filter:       key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "filter col")),
+		Replace:      key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "replace")),
+		Undo:         key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "undo")),
+		Redo:         key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("^r", "redo")),
+		Freeze:       key.NewBinding(key.WithKeys("Z"), key.WithHelp("Z", "freeze panes")),
+		Window:       key.NewBinding(key.WithKeys("ctrl+w"), key.WithHelp("^w s/v/w/q", "split panes")),
+		Collapse:     key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "collapse group")),
+		Expand:       key.NewBinding(key.WithKeys("+"), key.WithHelp("+", "expand group")),
+		Comment:      key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "comment")),
+		OutlineLevel: key.NewBinding(key.WithKeys("alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8"), key.WithHelp("alt+1-8", "outline level")),
 	}
 }
@@ -0,0 +1,350 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// minScrollWidth is the room frozen columns leave for the scrolled part of
+// the grid: a narrow column and its separator
+const minScrollWidth = 3
+
+// maxAutoLines caps the height of a row sized to fit its wrapped text
+const maxAutoLines = 8
+
+// gridRow is a sheet row drawn in the grid and the screen lines it takes
+type gridRow struct {
+	row   int
+	lines int
+}
+
+// gridLines returns the number of screen lines available to data rows in
+// the focused pane
+func (m Model) gridLines() int {
+	return m.activeRect().lines
+}
+
+// displayText returns the text a cell shows: its value, or its formula when
+// formulas are shown
+func (m Model) displayText(cell models.Cell) string {
+	if m.showFormulas && cell.Formula != "" {
+		return "=" + cell.Formula
+	}
+	return cell.Value
+}
+
+// rowHeight returns the number of lines a row is drawn over: its explicit
+// height, or with wrapping on, enough lines for its longest wrapped cell
+func (m Model) rowHeight(row int) int {
+	sheet := &m.sheets[m.currentSheet]
+	if h, ok := sheet.RowHeights[row]; ok && h > 0 {
+		return h
+	}
+	if !m.wrapText || row >= len(sheet.Rows) {
+		return 1
+	}
+
+	lines := 1
+	for col, cell := range sheet.Rows[row] {
+		text := m.displayText(cell)
+		width := colWidth(*sheet, col)
+		if !strings.Contains(text, "\n") && ui.DisplayWidth(text) <= width {
+			continue
+		}
+		lines = ui.Max(lines, len(ui.WrapLines(text, width)))
+	}
+	return ui.Min(lines, maxAutoLines)
+}
+
+// frozenRows returns how many grid positions at the top are frozen: as many
+// of the sheet's frozen rows as fit while leaving a line to scroll the rest
+func (m Model) frozenRows() int {
+	limit := ui.Min(m.rowPosition(m.sheets[m.currentSheet].FreezeRows), m.shownRows())
+	used, frozen := 0, 0
+	for frozen < limit {
+		used += m.rowHeight(m.rowAt(frozen))
+		if used > m.gridLines()-1 {
+			break
+		}
+		frozen++
+	}
+	return frozen
+}
+
+// scrollLines returns the screen lines left for rows below the frozen ones
+func (m Model) scrollLines() int {
+	lines := m.gridLines()
+	for pos := 0; pos < m.frozenRows(); pos++ {
+		lines -= m.rowHeight(m.rowAt(pos))
+	}
+	return lines
+}
+
+// gridRows returns the rows drawn in the grid: the frozen rows followed by
+// the shown rows from the scroll offset that fit. The first scrolled row is
+// always drawn, cut short if it is taller than the lines left.
+func (m Model) gridRows() []gridRow {
+	frozen := m.frozenRows()
+	var rows []gridRow
+	for pos := 0; pos < frozen; pos++ {
+		row := m.rowAt(pos)
+		rows = append(rows, gridRow{row: row, lines: m.rowHeight(row)})
+	}
+
+	left := m.scrollLines()
+	shown := m.shownRows()
+	for pos := ui.Max(m.rowPosition(m.offsetRow), frozen); pos < shown && left > 0; pos++ {
+		row := m.rowAt(pos)
+		lines := m.rowHeight(row)
+		if lines > left && pos > ui.Max(m.rowPosition(m.offsetRow), frozen) {
+			break
+		}
+		lines = ui.Min(lines, left)
+		rows = append(rows, gridRow{row: row, lines: lines})
+		left -= lines
+	}
+	return rows
+}
+
+// firstFitting returns the smallest grid position from which the rows down
+// to pos fit in lines screen lines, not going above the frozen rows
+func (m Model) firstFitting(pos, lines int) int {
+	frozen := m.frozenRows()
+	used := m.rowHeight(m.rowAt(pos))
+	for pos > frozen {
+		used += m.rowHeight(m.rowAt(pos - 1))
+		if used > lines {
+			break
+		}
+		pos--
+	}
+	return pos
+}
+
+// pageRows returns how many rows a page up or down moves: the rows that
+// scroll on screen
+func (m Model) pageRows() int {
+	return ui.Max(1, len(m.gridRows())-m.frozenRows())
+}
+
+// colWidth returns the width a column is drawn with
+func colWidth(sheet models.Sheet, col int) int {
+	if w, ok := sheet.ColWidths[col]; ok && w > 0 {
+		return w
+	}
+	return ui.MinCellWidth
+}
+
+// colSpan returns the screen width a column takes with its separator, or
+// zero when it is hidden
+func colSpan(sheet models.Sheet, col int) int {
+	if sheet.HiddenCols[col] {
+		return 0
+	}
+	return colWidth(sheet, col) + 1
+}
+
+// gridWidth returns the screen width available to columns in the focused
+// pane, right of the row number gutter and its separator
+func (m Model) gridWidth() int {
+	return ui.Max(1, m.activeRect().width-m.gutterWidth()-1)
+}
+
+// frozenCols returns how many columns at the left are frozen: as many of the
+// sheet's frozen columns as fit while leaving room to scroll the rest
+func (m Model) frozenCols() int {
+	sheet := m.sheets[m.currentSheet]
+	limit := m.gridWidth() - minScrollWidth
+	used, frozen := 0, 0
+	for frozen < ui.Min(sheet.FreezeCols, sheet.MaxCols-1) {
+		used += colSpan(sheet, frozen)
+		if used > limit {
+			break
+		}
+		frozen++
+	}
+	return frozen
+}
+
+// frozenWidth returns the screen width taken by the frozen columns
+func (m Model) frozenWidth() int {
+	sheet := m.sheets[m.currentSheet]
+	width := 0
+	for col := 0; col < m.frozenCols(); col++ {
+		width += colSpan(sheet, col)
+	}
+	return width
+}
+
+// colsFit reports whether the columns from..to fit on screen beside the
+// frozen columns, each followed by its separator
+func (m Model) colsFit(from, to int) bool {
+	sheet := m.sheets[m.currentSheet]
+	width := m.frozenWidth()
+	for col := from; col <= to; col++ {
+		width += colSpan(sheet, col)
+	}
+	return width <= m.gridWidth()
+}
+
+// gridCols returns the shown columns drawn in the grid: the frozen columns
+// followed by as many columns from the scroll offset as fit. The first
+// scrolled column is always drawn, cut off by the screen edge if it is too
+// wide.
+func (m Model) gridCols() []int {
+	sheet := m.sheets[m.currentSheet]
+	frozen := m.frozenCols()
+	var cols []int
+	for col := 0; col < frozen; col++ {
+		if !sheet.HiddenCols[col] {
+			cols = append(cols, col)
+		}
+	}
+	start := ui.Max(m.offsetCol, frozen)
+	scrolled := 0
+	for col := start; col < sheet.MaxCols; col++ {
+		if sheet.HiddenCols[col] {
+			continue
+		}
+		if scrolled > 0 && !m.colsFit(start, col) {
+			break
+		}
+		cols = append(cols, col)
+		scrolled++
+	}
+	return cols
+}
+
+// scrollColsTo moves the column offset so that the cursor column is on
+// screen, scrolling as little as possible
+func (m *Model) scrollColsTo() {
+	frozen := m.frozenCols()
+	m.offsetCol = ui.Max(m.offsetCol, frozen)
+	if m.cursorCol < frozen {
+		return
+	}
+	if m.cursorCol < m.offsetCol {
+		m.offsetCol = m.cursorCol
+		return
+	}
+
+	// Walk left from the cursor to the first column that still fits
+	sheet := m.sheets[m.currentSheet]
+	first := m.cursorCol
+	width := m.frozenWidth() + colSpan(sheet, first)
+	for first > frozen {
+		width += colSpan(sheet, first-1)
+		if width > m.gridWidth() {
+			break
+		}
+		first--
+	}
+	m.offsetCol = ui.Max(m.offsetCol, first)
+}
+
+// centerCols moves the column offset so that the cursor column is near the
+// middle of the scrolled part of the grid
+func (m *Model) centerCols() {
+	frozen := m.frozenCols()
+	m.offsetCol = ui.Max(frozen, m.cursorCol)
+	if m.cursorCol < frozen {
+		return
+	}
+
+	sheet := m.sheets[m.currentSheet]
+	half := (m.gridWidth() - m.frozenWidth()) / 2
+	width := (colWidth(sheet, m.cursorCol) + 1) / 2
+	for m.offsetCol > frozen {
+		width += colSpan(sheet, m.offsetCol-1)
+		if width > half {
+			break
+		}
+		m.offsetCol--
+	}
+}
+
+// lastOffsetCol returns the largest column offset worth scrolling to: the
+// one that shows the last column at the right edge
+func (m Model) lastOffsetCol() int {
+	sheet := m.sheets[m.currentSheet]
+	last := ui.Max(0, sheet.MaxCols-1)
+	offset := ui.Max(last, m.frozenCols())
+	for offset > m.frozenCols() && m.colsFit(offset-1, last) {
+		offset--
+	}
+	return offset
+}
+
+// autoFit sizes columns to the widest text they show, counting display
+// width so that wide characters and emoji are measured as drawn. Rows hidden
+// by filters are not measured.
+func (m *Model) autoFit(startCol, endCol int) {
+	if !m.loadAllRows(m.currentSheet) {
+		return
+	}
+	sheet := &m.sheets[m.currentSheet]
+	step := m.newStep("auto-fit "+m.outlineAxis(true).label(startCol, endCol), stepLayout)
+	if sheet.ColWidths == nil {
+		sheet.ColWidths = make(map[int]int)
+	}
+
+	for col := startCol; col <= endCol; col++ {
+		width := ui.DisplayWidth(ui.ColIndexToLetter(col))
+		for row := range sheet.Rows {
+			if col >= len(sheet.Rows[row]) || !rowShown(m.rowView, row) {
+				continue
+			}
+			width = ui.Max(width, ui.DisplayWidth(m.displayText(sheet.Rows[row][col])))
+		}
+		sheet.ColWidths[col] = ui.Max(2, ui.Min(width, ui.MaxCellWidth))
+	}
+	m.adjustViewport()
+	m.recordStep(step)
+
+	message := fmt.Sprintf("Auto-fit %d columns", endCol-startCol+1)
+	if startCol == endCol {
+		message = fmt.Sprintf("Auto-fit %s to width %d", ui.ColIndexToLetter(startCol), sheet.ColWidths[startCol])
+	}
+	m.status = models.StatusMsg{Message: message, Type: models.StatusSuccess}
+}
+
+// toggleWrap turns word wrapping of long and multi-line cells on or off.
+// Rows with wrapping on grow to fit their text, up to maxAutoLines.
+func (m *Model) toggleWrap() {
+	m.wrapText = !m.wrapText
+	m.adjustViewport()
+	if m.wrapText {
+		m.status = models.StatusMsg{Message: "Wrapping text", Type: models.StatusInfo}
+	} else {
+		m.status = models.StatusMsg{Message: "Not wrapping text", Type: models.StatusInfo}
+	}
+}
+
+// setRowHeight gives rows startRow..endRow an explicit height in lines, or
+// returns them to automatic height when lines is zero
+func (m *Model) setRowHeight(startRow, endRow, lines int) {
+	sheet := &m.sheets[m.currentSheet]
+	step := m.newStep("set height of "+m.outlineAxis(false).label(startRow, endRow), stepLayout)
+	if sheet.RowHeights == nil {
+		sheet.RowHeights = make(map[int]int)
+	}
+	for row := startRow; row <= endRow; row++ {
+		if lines > 0 {
+			sheet.RowHeights[row] = lines
+		} else {
+			delete(sheet.RowHeights, row)
+		}
+	}
+	m.adjustViewport()
+	m.recordStep(step)
+
+	rows := plural(endRow-startRow+1, "row")
+	if lines > 0 {
+		m.status = models.StatusMsg{Message: fmt.Sprintf("Height of %s: %s", rows, plural(lines, "line")), Type: models.StatusSuccess}
+	} else {
+		m.status = models.StatusMsg{Message: fmt.Sprintf("Height of %s: auto", rows), Type: models.StatusSuccess}
+	}
+}
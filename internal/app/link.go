@@ -0,0 +1,131 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/loader"
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// linkOpenedMsg reports how handing a URL to the opener went
+type linkOpenedMsg struct {
+	url string
+	err error
+}
+
+// defaultOpener returns the command URLs are opened with: $VEX_OPENER, or
+// the system's own opener
+func defaultOpener() string {
+	if opener := os.Getenv("VEX_OPENER"); opener != "" {
+		return opener
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return "open"
+	case "windows":
+		return "rundll32 url.dll,FileProtocolHandler"
+	}
+	return "xdg-open"
+}
+
+// SetOpener sets the command external links are opened with. The URL is
+// passed as its last argument.
+func (m *Model) SetOpener(command string) {
+	m.opener = command
+}
+
+// cursorLink returns the hyperlink of the cursor cell, if any
+func (m Model) cursorLink() string {
+	sheet := m.sheets[m.currentSheet]
+	return cellOrEmpty(&sheet, m.cursorRow, m.cursorCol).Link
+}
+
+// followLink follows the cursor cell's hyperlink: a place in the workbook is
+// jumped to, and a URL is handed to the opener
+func (m *Model) followLink() tea.Cmd {
+	link := m.cursorLink()
+	if link == "" {
+		m.status = models.StatusMsg{Message: "No link in " + ui.ColIndexToLetter(m.cursorCol) + fmt.Sprintf("%d", m.cursorRow+1), Type: models.StatusInfo}
+		return nil
+	}
+	if location, ok := strings.CutPrefix(link, "#"); ok {
+		m.followLocation(location)
+		return nil
+	}
+	return m.openLink(link)
+}
+
+// followLocation moves the cursor to a place in the workbook, switching to
+// its sheet
+func (m *Model) followLocation(location string) {
+	sheetName, cell, ok := loader.ParseLocation(location)
+	if !ok {
+		m.status = models.StatusMsg{Message: fmt.Sprintf("Cannot follow %q", location), Type: models.StatusWarning}
+		return
+	}
+	idx := m.currentSheet
+	if sheetName != "" {
+		if idx = m.sheetIndex(sheetName); idx < 0 {
+			m.status = models.StatusMsg{Message: fmt.Sprintf("No sheet named %q", sheetName), Type: models.StatusWarning}
+			return
+		}
+	}
+	if idx != m.currentSheet {
+		m.currentSheet = idx
+		m.isSelecting = false
+		m.resetView()
+	}
+	m.jumpToCell(cell)
+	if m.status.Type == models.StatusSuccess {
+		m.status.Message = fmt.Sprintf("→ %s!%s", m.sheets[idx].Name, cell)
+	}
+}
+
+// openLink hands a URL to the opener command, which runs in the background
+func (m *Model) openLink(url string) tea.Cmd {
+	fields := strings.Fields(m.opener)
+	if len(fields) == 0 {
+		m.status = models.StatusMsg{Message: "No opener for links (set --opener or $VEX_OPENER)", Type: models.StatusWarning}
+		return nil
+	}
+	m.status = models.StatusMsg{Message: "Opening " + url, Type: models.StatusInfo}
+	return func() tea.Msg {
+		cmd := exec.Command(fields[0], append(fields[1:], url)...)
+		return linkOpenedMsg{url: url, err: cmd.Run()}
+	}
+}
+
+// openedLink reports the result of openLink
+func (m *Model) openedLink(msg linkOpenedMsg) {
+	if msg.err != nil {
+		m.status = models.StatusMsg{Message: fmt.Sprintf("Opening %s failed: %v", msg.url, msg.err), Type: models.StatusError}
+		return
+	}
+	m.status = models.StatusMsg{Message: "Opened " + msg.url, Type: models.StatusSuccess}
+}
+
+// terminalLink reports whether a link can be made clickable in the terminal
+// with OSC 8: URLs can, places in the workbook cannot
+func terminalLink(link string) bool {
+	return link != "" && !strings.HasPrefix(link, "#") && strings.Contains(link, ":")
+}
+
+// hyperlink wraps text in an OSC 8 escape sequence that makes it a link to
+// url in terminals that support it
+func hyperlink(url, text string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// hyperlinkCost returns how much wider the OSC 8 sequence for url makes a
+// line as lipgloss and Bubble Tea measure it. They read most of the URL as
+// text, so lines only get links while that still fits the screen.
+func hyperlinkCost(url string) int {
+	return lipgloss.Width(hyperlink(url, ""))
+}
@@ -1,6 +1,10 @@
 package app
 
 import (
+	"fmt"
+	"regexp"
+
+	"github.com/CodeOne45/vex-tui/internal/loader"
 	"github.com/CodeOne45/vex-tui/internal/theme"
 	"github.com/CodeOne45/vex-tui/internal/ui"
 	"github.com/CodeOne45/vex-tui/pkg/models"
@@ -24,10 +28,13 @@ type Model struct {
 	exportInput   textinput.Model
 	editInput     textinput.Model
 	saveAsInput   textinput.Model
+	commandInput  textinput.Model
 	searchQuery   string
-	searchResults []models.Cell
+	searchResults []models.SearchResult
 	searchIndex   int
+	searchOptions models.SearchOptions
 	showFormulas  bool
+	wrapText      bool
 	status        models.StatusMsg
 	help          help.Model
 	keys          KeyMap
@@ -35,12 +42,78 @@ type Model struct {
 	themeName     string
 	styles        *ui.Styles
 
+	// Command external links are opened with
+	opener string
+
+	// Open files; the active one is mirrored in the fields above
+	buffers       []buffer
+	currentBuffer int
+	bufferCursor  int
+
+	// Rows shown by the current sheet's filters, nil when unfiltered
+	rowView []int
+
+	// Filter dialog
+	filterInput   textinput.Model
+	filterOp      models.FilterOp
+	filterValues  []string
+	filterMore    bool
+	filterChecked map[string]bool
+	filterCursor  int
+
+	// Find/replace dialog and the replacement in progress
+	replaceFind     textinput.Model
+	replaceWith     textinput.Model
+	replaceFocus    int
+	replaceFormulas bool
+	replaceRe       *regexp.Regexp
+	replaceTemplate string
+	replaceMatches  []models.SearchResult
+	replaceIndex    int
+	replaceStep     editStep
+	replaceCount    int
+
+	// Edit history, oldest first. savedStep is the undo depth at the last
+	// save, or -1 when that state can no longer be reached.
+	undoStack []editStep
+	redoStack []editStep
+	savedStep int
+
+	// Yank registers keyed by name; a " prefix picks the register for the
+	// next yank or put
+	registers        map[rune]register
+	awaitingRegister bool
+	pendingRegister  rune
+	lastYankText     string
+
+	// Export
+	exportScope int
+
 	// Chart visualization
 	chartType   int
 	selectStart [2]int
 	selectEnd   [2]int
 	isSelecting bool
 
+	// Mouse drags: the cell a drag selection started from, or the column
+	// whose separator is being dragged and the undo step of its resize
+	mouseDrag  mouseDrag
+	dragAnchor [2]int
+	resizeCol  int
+	resizeStep editStep
+
+	// Split panes, nil when there is just one. The focused pane's view is
+	// in the fields above; unfocused marks a copy drawing another pane.
+	panes          []pane
+	activePane     int
+	splitVertical  bool
+	unfocused      bool
+	awaitingWindow bool
+
+	// Choices of the list validation of the cell being edited
+	pickChoices []string
+	pickCursor  int
+
 	// Edit mode
 	isEditing   bool
 	modified    bool
@@ -68,7 +141,7 @@ func NewModel(filename string, sheets []models.Sheet, themeName string) Model {
 	jumpInput.Width = 30
 
 	exportInput := textinput.New()
-	exportInput.Placeholder = "filename.csv or .json"
+	exportInput.Placeholder = "filename.csv, .md, .html, .sql..."
 	exportInput.CharLimit = 100
 	exportInput.Width = 40
 
@@ -82,12 +155,27 @@ func NewModel(filename string, sheets []models.Sheet, themeName string) Model {
 	saveAsInput.CharLimit = 200
 	saveAsInput.Width = 40
 
-	fileFormat := "xlsx"
-	if len(filename) > 4 && filename[len(filename)-4:] == ".csv" {
-		fileFormat = "csv"
-	}
+	commandInput := textinput.New()
+	commandInput.Placeholder = "sql SELECT ..."
+	commandInput.CharLimit = 2000
+	commandInput.Width = 80
+
+	filterInput := textinput.New()
+	filterInput.Placeholder = "value"
+	filterInput.CharLimit = 200
+	filterInput.Width = 40
+
+	replaceFind := textinput.New()
+	replaceFind.Placeholder = "find"
+	replaceFind.CharLimit = 200
+	replaceFind.Width = 40
 
-	return Model{
+	replaceWith := textinput.New()
+	replaceWith.Placeholder = "replace with"
+	replaceWith.CharLimit = 200
+	replaceWith.Width = 40
+
+	m := Model{
 		sheets:       sheets,
 		currentSheet: 0,
 		searchInput:  searchInput,
@@ -95,17 +183,26 @@ func NewModel(filename string, sheets []models.Sheet, themeName string) Model {
 		exportInput:  exportInput,
 		editInput:    editInput,
 		saveAsInput:  saveAsInput,
+		commandInput: commandInput,
+		filterInput:  filterInput,
+		replaceFind:  replaceFind,
+		replaceWith:  replaceWith,
 		help:         help.New(),
 		keys:         DefaultKeyMap(),
 		filename:     filename,
 		themeName:    themeName,
 		styles:       styles,
-		fileFormat:   fileFormat,
+		opener:       defaultOpener(),
+		fileFormat:   loader.FileFormat(filename),
+		buffers:      make([]buffer, 1),
+		registers:    make(map[rune]register),
 		status: models.StatusMsg{
 			Message: "Ready • " + theme.GetCurrentTheme().Name,
 			Type:    models.StatusInfo,
 		},
 	}
+	m.applyFilters()
+	return m
 }
 
 // GetThemeNames returns available theme names
@@ -119,43 +216,109 @@ func (m *Model) resetView() {
 	m.cursorCol = 0
 	m.offsetRow = 0
 	m.offsetCol = 0
+	m.applyFilters()
 }
 
 // adjustViewport adjusts the viewport to keep cursor visible
 func (m *Model) adjustViewport() {
-	visibleRows := ui.Max(1, m.height-9)
-	visibleCols := ui.Max(1, (m.width-8)/(ui.MinCellWidth+2))
-
-	if m.cursorRow < m.offsetRow {
-		m.offsetRow = m.cursorRow
-	} else if m.cursorRow >= m.offsetRow+visibleRows {
-		m.offsetRow = m.cursorRow - visibleRows + 1
+	// Rows are counted in grid positions so that filtered and hidden rows are
+	// skipped, and fitted on screen by the lines they take. Frozen rows and
+	// columns stay put and only the rest scrolls.
+	if shown := m.shownRows(); shown > 0 {
+		m.snapCursorRow()
+		frozen := m.frozenRows()
+		cursorPos := m.rowPosition(m.cursorRow)
+		offsetPos := ui.Max(m.rowPosition(m.offsetRow), frozen)
+		if cursorPos >= frozen {
+			if cursorPos < offsetPos {
+				offsetPos = cursorPos
+			} else {
+				offsetPos = ui.Max(offsetPos, m.firstFitting(cursorPos, m.scrollLines()))
+			}
+		}
+		m.offsetRow = m.rowAt(ui.Min(offsetPos, shown-1))
 	}
 
-	if m.cursorCol < m.offsetCol {
-		m.offsetCol = m.cursorCol
-	} else if m.cursorCol >= m.offsetCol+visibleCols {
-		m.offsetCol = m.cursorCol - visibleCols + 1
-	}
+	m.snapCursorCol()
+	m.scrollColsTo()
+
+	m.ensureRowsLoaded()
 }
 
 // centerView centers the viewport on the current cursor
 func (m *Model) centerView() {
-	visibleRows := ui.Max(1, m.height-9)
-	visibleCols := ui.Max(1, (m.width-8)/(ui.MinCellWidth+2))
+	if shown := m.shownRows(); shown > 0 {
+		m.snapCursorRow()
+		cursorPos := m.rowPosition(m.cursorRow)
+		offsetPos := ui.Max(m.frozenRows(), cursorPos)
+		if cursorPos >= m.frozenRows() {
+			offsetPos = m.firstFitting(cursorPos, m.scrollLines()/2+1)
+		}
+		m.offsetRow = m.rowAt(ui.Min(offsetPos, shown-1))
+	}
+	m.snapCursorCol()
+	m.centerCols()
+
+	m.ensureRowsLoaded()
+}
 
-	m.offsetRow = ui.Max(0, m.cursorRow-visibleRows/2)
-	m.offsetCol = ui.Max(0, m.cursorCol-visibleCols/2)
+// ensureRowsLoaded fetches rows of a lazily loaded sheet so that everything
+// down to the bottom of the viewport is in memory
+func (m *Model) ensureRowsLoaded() {
+	sheet := &m.sheets[m.currentSheet]
+	if sheet.Source == nil || sheet.Source.Complete() {
+		return
+	}
+
+	upto := ui.Max(m.cursorRow, m.offsetRow+m.gridLines()) + 1
+	if upto <= len(sheet.Rows) {
+		return
+	}
+	if err := sheet.Source.LoadRows(sheet, upto); err != nil {
+		m.status = models.StatusMsg{Message: fmt.Sprintf("Load failed: %v", err), Type: models.StatusError}
+	}
 }
 
-// isSearchMatch checks if a cell is a search match
-func (m *Model) isSearchMatch(row, col int) bool {
-	for _, result := range m.searchResults {
-		if result.Row == row && result.Col == col {
-			return true
+// loadAllRows fetches every remaining row of the given sheets, for operations
+// such as search and export that need the whole sheet in memory
+func (m *Model) loadAllRows(sheetIdxs ...int) bool {
+	for _, idx := range sheetIdxs {
+		if err := loader.LoadAllRows(&m.sheets[idx]); err != nil {
+			m.status = models.StatusMsg{Message: fmt.Sprintf("Load failed: %v", err), Type: models.StatusError}
+			return false
 		}
 	}
-	return false
+	return true
+}
+
+// allSheetIndexes returns the index of every sheet in the workbook
+func (m Model) allSheetIndexes() []int {
+	idxs := make([]int, len(m.sheets))
+	for i := range idxs {
+		idxs[i] = i
+	}
+	return idxs
+}
+
+// displayName returns the file name shown in the title bar
+func (m Model) displayName() string {
+	if m.filename == "" {
+		return "[stdin]"
+	}
+	return m.filename
+}
+
+// selectionBounds returns the normalized selection as start and end row/col
+func (m Model) selectionBounds() (startRow, startCol, endRow, endCol int) {
+	startRow, startCol = m.selectStart[0], m.selectStart[1]
+	endRow, endCol = m.selectEnd[0], m.selectEnd[1]
+	if startRow > endRow {
+		startRow, endRow = endRow, startRow
+	}
+	if startCol > endCol {
+		startCol, endCol = endCol, startCol
+	}
+	return startRow, startCol, endRow, endCol
 }
 
 // applyTheme applies a new theme and reinitializes styles
@@ -0,0 +1,324 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Screen layout of the normal view: the title, the formula bar and the sheet
+// tab line come before the column headers, and the row numbers are as wide
+// as the RowNum style
+const (
+	tabLine     = 2
+	headerLine  = 3
+	rowNumWidth = 5
+)
+
+// wheelStep is the number of rows or columns one wheel notch scrolls
+const wheelStep = 3
+
+// mouseDrag is what a held left button is doing
+type mouseDrag int
+
+const (
+	dragNone mouseDrag = iota
+	dragSelect
+	dragResize
+)
+
+// sheetTab is a sheet's label in the tab line and the screen columns it
+// covers
+type sheetTab struct {
+	sheet      int
+	label      string
+	start, end int
+}
+
+// updateMouse handles the mouse in normal and range selection modes: clicks
+// move the cursor or switch sheets, dragging selects a range or resizes a
+// column and the wheel scrolls
+func (m Model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if len(m.sheets) == 0 || (m.mode != models.ModeNormal && m.mode != models.ModeSelectRange) {
+		return m, nil
+	}
+	m.quitConfirm = false
+	x, y := m.paneMouse(msg)
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		delta := wheelStep
+		if msg.Button == tea.MouseButtonWheelUp {
+			delta = -wheelStep
+		}
+		if msg.Shift {
+			m.scrollCols(delta)
+		} else {
+			m.scrollRows(delta)
+		}
+		return m, nil
+	case tea.MouseButtonWheelLeft:
+		m.scrollCols(-wheelStep)
+		return m, nil
+	case tea.MouseButtonWheelRight:
+		m.scrollCols(wheelStep)
+		return m, nil
+	}
+
+	switch msg.Action {
+	case tea.MouseActionPress:
+		if msg.Button == tea.MouseButtonLeft {
+			m.mousePress(x, y)
+		}
+	case tea.MouseActionMotion:
+		m.mouseMotion(x, y)
+	case tea.MouseActionRelease:
+		// A resize is one step however far the separator moved
+		if m.mouseDrag == dragResize {
+			m.recordStep(m.resizeStep)
+		}
+		m.mouseDrag = dragNone
+	}
+	return m, nil
+}
+
+// paneMouse returns the mouse position relative to the pane it is over, as
+// if that pane filled the grid. A click or wheel over another pane focuses
+// it; drags stay with the pane they started in.
+func (m *Model) paneMouse(msg tea.MouseMsg) (x, y int) {
+	if len(m.panes) < 2 || msg.Y < headerLine {
+		return msg.X, msg.Y
+	}
+	if msg.Action == tea.MouseActionPress && m.mouseDrag == dragNone {
+		if idx, ok := m.paneAt(msg.X, msg.Y); ok {
+			m.focusPane(idx)
+		}
+	}
+	rect := m.activeRect()
+	return msg.X - rect.x, msg.Y - rect.y + headerLine
+}
+
+// mousePress handles a left click
+func (m *Model) mousePress(x, y int) {
+	switch {
+	case y == tabLine:
+		for _, tab := range m.sheetTabs() {
+			if x >= tab.start && x < tab.end && tab.sheet != m.currentSheet {
+				m.currentSheet = tab.sheet
+				m.resetView()
+				m.status = models.StatusMsg{Message: fmt.Sprintf("→ %s", m.sheets[m.currentSheet].Name), Type: models.StatusInfo}
+			}
+		}
+	case y == headerLine:
+		col, edge, ok := m.colAt(x)
+		if !ok {
+			return
+		}
+		if edge {
+			m.mouseDrag = dragResize
+			m.resizeCol = col
+			m.resizeStep = m.newStep("resize column "+ui.ColIndexToLetter(col), stepLayout)
+			return
+		}
+		m.cursorCol = col
+		m.adjustViewport()
+	case x < m.outlineWidth():
+		// The outline gutter collapses and expands the row's group
+		if row, ok := m.rowAtLine(y); ok {
+			m.cursorRow = row
+			m.toggleGroup(false)
+		}
+	default:
+		row, col, ok := m.cellAt(x, y)
+		if !ok {
+			return
+		}
+		m.cursorRow, m.cursorCol = row, col
+		if m.mode == models.ModeSelectRange {
+			m.selectEnd = [2]int{row, col}
+		} else {
+			m.isSelecting = false
+			m.mouseDrag = dragSelect
+			m.dragAnchor = [2]int{row, col}
+		}
+		m.adjustViewport()
+	}
+}
+
+// mouseMotion extends a drag selection or resizes a column. Dragging past
+// the edge of the grid scrolls it.
+func (m *Model) mouseMotion(x, y int) {
+	switch m.mouseDrag {
+	case dragResize:
+		start, ok := m.colStart(m.resizeCol)
+		if !ok {
+			return
+		}
+		width := ui.Max(2, ui.Min(x-start, ui.MaxCellWidth))
+		sheet := &m.sheets[m.currentSheet]
+		if sheet.ColWidths == nil {
+			sheet.ColWidths = make(map[int]int)
+		}
+		sheet.ColWidths[m.resizeCol] = width
+		m.status = models.StatusMsg{Message: fmt.Sprintf("Width: %d", width), Type: models.StatusInfo}
+
+	case dragSelect:
+		if row, ok := m.rowAtLine(y); ok {
+			m.cursorRow = row
+		} else if y <= headerLine {
+			m.moveCursorRows(-1)
+		} else {
+			m.moveCursorRows(1)
+		}
+
+		if col, _, ok := m.colAt(x); ok {
+			m.cursorCol = col
+			m.adjustViewport()
+		} else if x > m.gutterWidth() {
+			m.moveCursorCols(1)
+		} else {
+			m.moveCursorCols(-1)
+		}
+
+		if [2]int{m.cursorRow, m.cursorCol} != m.dragAnchor || m.isSelecting {
+			m.isSelecting = true
+			m.selectStart = m.dragAnchor
+			m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
+		}
+	}
+}
+
+// cellAt returns the sheet cell drawn at screen position x, y
+func (m Model) cellAt(x, y int) (row, col int, ok bool) {
+	row, ok = m.rowAtLine(y)
+	if !ok {
+		return 0, 0, false
+	}
+	if x < m.gutterWidth() {
+		// The row number gutter selects the row's cell in the cursor column
+		return row, m.cursorCol, true
+	}
+	col, _, ok = m.colAt(x)
+	return row, col, ok
+}
+
+// rowAtLine returns the sheet row drawn on screen line y, walking the grid
+// rows by the lines each takes
+func (m Model) rowAtLine(y int) (int, bool) {
+	line := headerLine + 1
+	for _, r := range m.gridRows() {
+		if y >= line && y < line+r.lines {
+			return r.row, true
+		}
+		line += r.lines
+	}
+	return 0, false
+}
+
+// colAt returns the column drawn at screen column x, walking the grid the
+// way renderTable lays it out. edge reports that x is the column's right
+// separator.
+func (m Model) colAt(x int) (col int, edge, ok bool) {
+	sheet := m.sheets[m.currentSheet]
+	left := m.gutterWidth() + 1
+	for _, col := range m.gridCols() {
+		right := left + colWidth(sheet, col)
+		if x >= left && x <= right {
+			return col, x == right, true
+		}
+		left = right + 1
+	}
+	return 0, false, false
+}
+
+// colStart returns the screen column where a column's cells begin
+func (m Model) colStart(target int) (int, bool) {
+	sheet := m.sheets[m.currentSheet]
+	left := m.gutterWidth() + 1
+	for _, col := range m.gridCols() {
+		if col == target {
+			return left, true
+		}
+		left += colWidth(sheet, col) + 1
+	}
+	return 0, false
+}
+
+// scrollRows scrolls the grid by delta shown rows, taking the cursor along
+// when it would leave the screen
+func (m *Model) scrollRows(delta int) {
+	shown := m.shownRows()
+	if shown == 0 {
+		return
+	}
+	frozen := m.frozenRows()
+
+	// The offset stops where the last row fits at the bottom
+	offsetPos := ui.Max(frozen, m.rowPosition(m.offsetRow))
+	offsetPos = ui.Max(frozen, ui.Min(offsetPos+delta, m.firstFitting(shown-1, m.scrollLines())))
+	m.offsetRow = m.rowAt(ui.Min(offsetPos, shown-1))
+
+	if cursorPos := m.rowPosition(m.cursorRow); cursorPos >= frozen {
+		rows := m.gridRows()
+		if len(rows) == 0 {
+			return
+		}
+		lastPos := m.rowPosition(rows[len(rows)-1].row)
+		cursorPos = ui.Max(offsetPos, ui.Min(cursorPos, lastPos))
+		m.cursorRow = m.rowAt(ui.Min(cursorPos, shown-1))
+	}
+	m.adjustViewport()
+}
+
+// scrollCols scrolls the grid by delta columns, taking the cursor along when
+// it would leave the screen
+func (m *Model) scrollCols(delta int) {
+	sheet := m.sheets[m.currentSheet]
+	frozen := m.frozenCols()
+	m.offsetCol = ui.Max(frozen, ui.Min(ui.Max(m.offsetCol, frozen)+delta, m.lastOffsetCol()))
+	if m.cursorCol >= frozen {
+		cols := m.gridCols()
+		if len(cols) == 0 {
+			return
+		}
+		m.cursorCol = ui.Max(m.offsetCol, ui.Min(m.cursorCol, cols[len(cols)-1]))
+		m.cursorCol = ui.Min(m.cursorCol, ui.Max(0, sheet.MaxCols-1))
+	}
+	m.adjustViewport()
+}
+
+// sheetTabs lays out the sheet tab line, starting late enough that the
+// current sheet's tab fits on screen
+func (m Model) sheetTabs() []sheetTab {
+	labels := make([]string, len(m.sheets))
+	for i, sheet := range m.sheets {
+		labels[i] = " " + ui.Truncate(sheet.Name, 24) + " "
+	}
+
+	first := 0
+	for {
+		width := 0
+		for i := first; i <= m.currentSheet; i++ {
+			width += lipgloss.Width(labels[i]) + 1
+		}
+		if width <= m.width || first == m.currentSheet {
+			break
+		}
+		first++
+	}
+
+	var tabs []sheetTab
+	x := 0
+	for i := first; i < len(labels); i++ {
+		end := x + lipgloss.Width(labels[i])
+		if end > m.width && i > m.currentSheet {
+			break
+		}
+		tabs = append(tabs, sheetTab{sheet: i, label: labels[i], start: x, end: end})
+		x = end + 1
+	}
+	return tabs
+}
@@ -0,0 +1,301 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// maxOutlineLevel is the deepest outline level Excel supports
+const maxOutlineLevel = 7
+
+// outlineAxis is the rows or the columns of the current sheet as outline
+// groups see them. As in Excel, the row below a group or the column right
+// of it is the group's summary.
+type outlineAxis struct {
+	levels map[int]int
+	count  int
+	cols   bool
+}
+
+// outlineAxis returns the rows, or the columns when cols is set, of the
+// current sheet
+func (m Model) outlineAxis(cols bool) outlineAxis {
+	sheet := m.sheets[m.currentSheet]
+	if cols {
+		return outlineAxis{levels: sheet.ColLevels, count: sheet.MaxCols, cols: true}
+	}
+	return outlineAxis{levels: sheet.RowLevels, count: sheet.MaxRows}
+}
+
+// run returns the bounds of the rows or columns around i grouped at level or
+// deeper
+func (a outlineAxis) run(i, level int) (start, end int) {
+	start, end = i, i
+	for start > 0 && a.levels[start-1] >= level {
+		start--
+	}
+	for end < a.count-1 && a.levels[end+1] >= level {
+		end++
+	}
+	return start, end
+}
+
+// closedGroup returns the group that i summarizes: the deeper group that
+// ends right before it
+func (a outlineAxis) closedGroup(i int) (start, end int, ok bool) {
+	if i <= 0 || a.levels[i-1] <= a.levels[i] {
+		return 0, 0, false
+	}
+	start, _ = a.run(i-1, a.levels[i]+1)
+	return start, i - 1, true
+}
+
+// enclosingGroup returns the innermost group i belongs to
+func (a outlineAxis) enclosingGroup(i int) (start, end int, ok bool) {
+	if a.levels[i] == 0 {
+		return 0, 0, false
+	}
+	start, end = a.run(i, a.levels[i])
+	return start, end, true
+}
+
+// label names the rows or columns start..end, such as rows 3-7 or columns C-F
+func (a outlineAxis) label(start, end int) string {
+	if a.cols {
+		if start == end {
+			return "column " + ui.ColIndexToLetter(start)
+		}
+		return fmt.Sprintf("columns %s-%s", ui.ColIndexToLetter(start), ui.ColIndexToLetter(end))
+	}
+	if start == end {
+		return fmt.Sprintf("row %d", start+1)
+	}
+	return fmt.Sprintf("rows %d-%d", start+1, end+1)
+}
+
+// outlineCursor returns the cursor row, or the cursor column when cols is set
+func (m Model) outlineCursor(cols bool) int {
+	if cols {
+		return m.cursorCol
+	}
+	return m.cursorRow
+}
+
+// hiddenCount returns how many of the rows or columns start..end are hidden
+func (m Model) hiddenCount(cols bool, start, end int) int {
+	sheet := m.sheets[m.currentSheet]
+	hidden := sheet.HiddenRows
+	if cols {
+		hidden = sheet.HiddenCols
+	}
+	count := 0
+	for i := start; i <= end; i++ {
+		if hidden[i] {
+			count++
+		}
+	}
+	return count
+}
+
+// collapseGroup hides the group the cursor row or column summarizes while
+// any of it is shown, or else the group the cursor is in. Hiding records
+// the undo step.
+func (m *Model) collapseGroup(cols bool) {
+	a := m.outlineAxis(cols)
+	i := m.outlineCursor(cols)
+	start, end, ok := a.closedGroup(i)
+	if !ok || m.hiddenCount(cols, start, end) == end-start+1 {
+		start, end, ok = a.enclosingGroup(i)
+	}
+	if !ok {
+		m.status = models.StatusMsg{Message: "No group here", Type: models.StatusInfo}
+		return
+	}
+
+	hidden := false
+	if cols {
+		hidden = m.hideCols(start, end)
+	} else {
+		hidden = m.hideRows(start, end)
+	}
+	if hidden {
+		m.status = models.StatusMsg{Message: "Collapsed " + a.label(start, end), Type: models.StatusSuccess}
+	}
+}
+
+// expandGroup shows the collapsed group the cursor row or column summarizes
+func (m *Model) expandGroup(cols bool) {
+	a := m.outlineAxis(cols)
+	start, end, ok := a.closedGroup(m.outlineCursor(cols))
+	if !ok || m.hiddenCount(cols, start, end) == 0 {
+		m.status = models.StatusMsg{Message: "No collapsed group here", Type: models.StatusInfo}
+		return
+	}
+	if cols {
+		m.unhideCols(start, end)
+	} else {
+		m.unhideRows(start, end)
+	}
+	m.status = models.StatusMsg{Message: "Expanded " + a.label(start, end), Type: models.StatusSuccess}
+}
+
+// toggleGroup expands the group the cursor row or column summarizes when it
+// is collapsed, and collapses it otherwise
+func (m *Model) toggleGroup(cols bool) {
+	a := m.outlineAxis(cols)
+	if start, end, ok := a.closedGroup(m.outlineCursor(cols)); ok && m.hiddenCount(cols, start, end) > 0 {
+		m.expandGroup(cols)
+		return
+	}
+	m.collapseGroup(cols)
+}
+
+// showOutlineLevel shows the grouped rows or columns above an outline level
+// and hides the deeper ones, like Excel's outline level buttons. Rows and
+// columns outside any group are left as they are.
+func (m *Model) showOutlineLevel(level int, cols bool) {
+	sheet := &m.sheets[m.currentSheet]
+	a := m.outlineAxis(cols)
+	hidden := &sheet.HiddenRows
+	if cols {
+		hidden = &sheet.HiddenCols
+	}
+	if len(a.levels) == 0 {
+		noun := "rows"
+		if cols {
+			noun = "columns"
+		}
+		m.status = models.StatusMsg{Message: "No grouped " + noun, Type: models.StatusInfo}
+		return
+	}
+
+	// Keep at least one row or column on screen
+	shown := false
+	for i := 0; i < a.count && !shown; i++ {
+		shown = a.levels[i] < level && (a.levels[i] > 0 || !(*hidden)[i])
+	}
+	if !shown {
+		m.status = models.StatusMsg{Message: fmt.Sprintf("Level %d would hide everything", level), Type: models.StatusWarning}
+		return
+	}
+
+	step := m.newStep(fmt.Sprintf("outline level %d", level), stepLayout)
+	if *hidden == nil {
+		*hidden = make(map[int]bool)
+	}
+	for i, l := range a.levels {
+		if l >= level {
+			(*hidden)[i] = true
+		} else {
+			delete(*hidden, i)
+		}
+	}
+	m.applyFilters()
+	m.adjustViewport()
+	m.recordStep(step)
+	m.status = models.StatusMsg{Message: fmt.Sprintf("Outline level %d", level), Type: models.StatusSuccess}
+}
+
+// groupSelection raises (delta 1) or lowers (delta -1) the outline level of
+// the selected rows or columns, or of the cursor's
+func (m *Model) groupSelection(cols bool, delta int) {
+	sheet := &m.sheets[m.currentSheet]
+	startRow, startCol, endRow, endCol := m.selectedRange()
+	start, end, levels := startRow, endRow, &sheet.RowLevels
+	if cols {
+		start, end, levels = startCol, endCol, &sheet.ColLevels
+	}
+	label := m.outlineAxis(cols).label(start, end)
+	verb := "group "
+	if delta < 0 {
+		verb = "ungroup "
+	}
+	step := m.newStep(verb+label, stepLayout)
+	if *levels == nil {
+		*levels = make(map[int]int)
+	}
+
+	changed := false
+	for i := start; i <= end; i++ {
+		level := ui.Max(0, ui.Min((*levels)[i]+delta, maxOutlineLevel))
+		if level == (*levels)[i] {
+			continue
+		}
+		changed = true
+		if level == 0 {
+			delete(*levels, i)
+		} else {
+			(*levels)[i] = level
+		}
+	}
+	m.isSelecting = false
+	m.adjustViewport()
+	m.recordStep(step)
+
+	switch {
+	case !changed && delta > 0:
+		m.status = models.StatusMsg{Message: fmt.Sprintf("Already at level %d: %s", maxOutlineLevel, label), Type: models.StatusInfo}
+	case !changed:
+		m.status = models.StatusMsg{Message: "Not grouped: " + label, Type: models.StatusInfo}
+	case delta > 0:
+		m.status = models.StatusMsg{Message: "Grouped " + label, Type: models.StatusSuccess}
+	default:
+		m.status = models.StatusMsg{Message: "Ungrouped " + label, Type: models.StatusSuccess}
+	}
+}
+
+// parseOutlineAxis reads the rows or cols argument of the outline commands;
+// rows is the default
+func parseOutlineAxis(arg string) (cols bool, err error) {
+	switch strings.ToLower(arg) {
+	case "", "row", "rows", "r":
+		return false, nil
+	case "col", "cols", "column", "columns", "c":
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown target %q (rows or cols)", arg)
+}
+
+// outlineWidth returns the width of the outline gutter left of the row
+// numbers: a column per level of row groups, or none without them
+func (m Model) outlineWidth() int {
+	width := 0
+	for _, level := range m.sheets[m.currentSheet].RowLevels {
+		width = ui.Max(width, level)
+	}
+	return width
+}
+
+// gutterWidth returns the width of the gutter left of the grid: the outline
+// gutter and the row numbers
+func (m Model) gutterWidth() int {
+	return m.outlineWidth() + rowNumWidth
+}
+
+// outlineMarks returns the outline gutter of a row: a bar for each level of
+// group it is in and, on the first line of a row that summarizes a group, +
+// when the group is collapsed or - when it is expanded
+func (m Model) outlineMarks(row int, first bool) string {
+	a := m.outlineAxis(false)
+	width := m.outlineWidth()
+	var b strings.Builder
+	for level := 1; level <= width; level++ {
+		switch {
+		case level <= a.levels[row]:
+			b.WriteString("│")
+		case first && row > 0 && a.levels[row-1] >= level:
+			start, _ := a.run(row-1, level)
+			if m.hiddenCount(false, start, row-1) == row-start {
+				b.WriteString("+")
+			} else {
+				b.WriteString("-")
+			}
+		default:
+			b.WriteString(" ")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	"github.com/atotto/clipboard"
+)
+
+// Special registers: the unnamed register used when no register is given,
+// and the system clipboard
+const (
+	unnamedRegister   = '"'
+	clipboardRegister = '+'
+)
+
+// register holds yanked cells with their formulas. Cells yanked from the
+// sheet remember where they came from so that relative references in their
+// formulas follow them when put elsewhere.
+type register struct {
+	cells    [][]models.Cell
+	row, col int
+	relative bool
+}
+
+// selectRegister handles the key after a " prefix
+func (m *Model) selectRegister(key string) {
+	m.awaitingRegister = false
+	runes := []rune(key)
+	if len(runes) != 1 || !(unicode.IsLetter(runes[0]) || runes[0] == unnamedRegister || runes[0] == clipboardRegister) {
+		m.status = models.StatusMsg{Message: "Registers are a-z, \" and +", Type: models.StatusWarning}
+		return
+	}
+	m.pendingRegister = unicode.ToLower(runes[0])
+	m.status = models.StatusMsg{Message: fmt.Sprintf("Register \"%c", m.pendingRegister), Type: models.StatusInfo}
+}
+
+// takeRegister returns the register chosen with a " prefix, or the unnamed
+// register, and clears the prefix
+func (m *Model) takeRegister() rune {
+	name := m.pendingRegister
+	m.pendingRegister = 0
+	if name == 0 {
+		return unnamedRegister
+	}
+	return name
+}
+
+// yank copies a block of the current sheet into a register. The unnamed
+// register is also written to the system clipboard as TSV.
+func (m *Model) yank(startRow, startCol, endRow, endCol int) bool {
+	name := m.takeRegister()
+	if !m.loadAllRows(m.currentSheet) {
+		return false
+	}
+
+	sheet := &m.sheets[m.currentSheet]
+	reg := register{row: startRow, col: startCol, relative: true}
+	for row := startRow; row <= endRow; row++ {
+		cells := make([]models.Cell, 0, endCol-startCol+1)
+		for col := startCol; col <= endCol; col++ {
+			cells = append(cells, cellOrEmpty(sheet, row, col))
+		}
+		reg.cells = append(reg.cells, cells)
+	}
+
+	message := fmt.Sprintf("Yanked %d×%d", endRow-startRow+1, endCol-startCol+1)
+	if endRow == startRow && endCol == startCol {
+		message = "Yanked " + ui.Truncate(reg.cells[0][0].Value, 30)
+	}
+
+	if name != clipboardRegister {
+		m.registers[name] = reg
+	}
+	if name != unnamedRegister {
+		m.registers[unnamedRegister] = reg
+	}
+	if name == unnamedRegister || name == clipboardRegister {
+		text := m.rangeText(startRow, startCol, endRow, endCol)
+		via, err := writeClipboard(text)
+		if err != nil {
+			m.status = models.StatusMsg{Message: "Failed to copy: " + err.Error(), Type: models.StatusError}
+			return false
+		}
+		m.lastYankText = text
+		message += " to " + via
+	} else {
+		message += fmt.Sprintf(" into \"%c", name)
+	}
+
+	m.status = models.StatusMsg{Message: message, Type: models.StatusSuccess}
+	return true
+}
+
+// put writes a register at the cursor, or repeated across the selection.
+// The unnamed register gives way to the system clipboard when something
+// else has been copied there since the last yank.
+func (m *Model) put() {
+	if !m.canEdit() {
+		return
+	}
+	name := m.takeRegister()
+
+	reg, ok := m.registers[name]
+	if name == unnamedRegister || name == clipboardRegister {
+		if text, err := readClipboard(); err == nil && text != "" && (name == clipboardRegister || text != m.lastYankText || !ok) {
+			reg, ok = textRegister(text), true
+		}
+	}
+	if !ok || len(reg.cells) == 0 {
+		m.status = models.StatusMsg{Message: fmt.Sprintf("Register \"%c is empty", name), Type: models.StatusWarning}
+		return
+	}
+
+	m.putCells(reg)
+}
+
+// textRegister parses tab-separated text into a register. Values starting
+// with = become formulas, used as written.
+func textRegister(text string) register {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var reg register
+	for _, line := range strings.Split(text, "\n") {
+		var cells []models.Cell
+		for _, value := range strings.Split(line, "\t") {
+			value = strings.TrimSpace(value)
+			if strings.HasPrefix(value, "=") {
+				cells = append(cells, models.Cell{Formula: value[1:]})
+			} else {
+				cells = append(cells, models.Cell{Value: value})
+			}
+		}
+		reg.cells = append(reg.cells, cells)
+	}
+	return reg
+}
+
+// putCells writes a register's cells at the cursor. With a selection the
+// block is repeated to fill it and nothing outside it is changed. Relative
+// references in formulas are shifted by the distance each cell moves.
+func (m *Model) putCells(reg register) {
+	sheet := &m.sheets[m.currentSheet]
+	width := 0
+	for _, line := range reg.cells {
+		width = ui.Max(width, len(line))
+	}
+
+	startRow, startCol := m.cursorRow, m.cursorCol
+	endRow, endCol := startRow+len(reg.cells)-1, startCol+width-1
+	if m.isSelecting {
+		startRow, startCol, endRow, endCol = m.selectedRange()
+	}
+	endRow = ui.Min(endRow, sheet.MaxRows-1)
+	endCol = ui.Min(endCol, sheet.MaxCols-1)
+
+	step := m.newStep("put", stepCells)
+	step.snapshot(sheet, startRow, startCol, endRow, endCol)
+	for row := startRow; row <= endRow; row++ {
+		i := (row - startRow) % len(reg.cells)
+		for col := startCol; col <= endCol; col++ {
+			j := (col - startCol) % width
+			source := models.Cell{}
+			if j < len(reg.cells[i]) {
+				source = reg.cells[i][j]
+			}
+
+			cell := ensureCell(sheet, row, col)
+			cell.Value, cell.Formula = source.Value, source.Formula
+			if source.Formula != "" {
+				if reg.relative {
+					cell.Formula = m.adjustFormulaReferences(source.Formula, row-(reg.row+i), col-(reg.col+j))
+				}
+				cell.Value = m.evaluateFormula(cell.Formula)
+			}
+		}
+	}
+
+	m.recalculateFormulas()
+	m.recordStep(step)
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Put %d×%d", endRow-startRow+1, endCol-startCol+1),
+		Type:    models.StatusSuccess,
+	}
+}
+
+// overSSH reports whether vex runs in an SSH session, where a clipboard tool
+// would reach the remote machine's clipboard rather than the user's
+func overSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// writeClipboard copies text to the system clipboard, falling back to an
+// OSC 52 escape sequence that asks the terminal to set its clipboard. It
+// returns which of the two was used.
+func writeClipboard(text string) (string, error) {
+	if !clipboard.Unsupported && !overSSH() {
+		if err := clipboard.WriteAll(text); err == nil {
+			return "clipboard", nil
+		}
+	}
+
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		// tmux passes the sequence on to the outer terminal when wrapped
+		seq = "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	if _, err := os.Stdout.WriteString(seq); err != nil {
+		return "", err
+	}
+	return "terminal (OSC 52)", nil
+}
+
+// readClipboard reads the system clipboard when a clipboard tool is
+// available. Terminals rarely allow reading through OSC 52, so over SSH or
+// without a tool only the registers are used.
+func readClipboard() (string, error) {
+	if clipboard.Unsupported || overSSH() {
+		return "", fmt.Errorf("no clipboard available")
+	}
+	return clipboard.ReadAll()
+}
@@ -0,0 +1,288 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/loader"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// backrefPattern matches \1 style group references in a replacement
+var backrefPattern = regexp.MustCompile(`\\(\d+)`)
+
+// openReplace opens the find/replace dialog, starting from the last search
+func (m *Model) openReplace() {
+	if !m.canEdit() {
+		return
+	}
+	m.replaceFind.SetValue(m.searchQuery)
+	m.replaceFind.CursorEnd()
+	m.replaceWith.SetValue("")
+	m.replaceFocus = 0
+	m.replaceFind.Focus()
+	m.replaceWith.Blur()
+	m.mode = models.ModeReplace
+}
+
+// updateReplace handles the find/replace dialog
+func (m Model) updateReplace(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "esc":
+		m.mode = models.ModeNormal
+		m.replaceFind.Blur()
+		m.replaceWith.Blur()
+		return m, nil
+	case "tab", "shift+tab", "up", "down":
+		m.replaceFocus = 1 - m.replaceFocus
+		if m.replaceFocus == 0 {
+			m.replaceFind.Focus()
+			m.replaceWith.Blur()
+		} else {
+			m.replaceWith.Focus()
+			m.replaceFind.Blur()
+		}
+		return m, textinput.Blink
+	case "enter", "alt+a":
+		m.replaceFind.Blur()
+		m.replaceWith.Blur()
+		m.mode = models.ModeNormal
+		m.startReplace(msg.String() == "alt+a")
+		return m, nil
+	case "alt+f":
+		// Replace has no values-only mode, so Alt+F toggles formula text
+		m.replaceFormulas = !m.replaceFormulas
+		return m, nil
+	}
+
+	if m.toggleSearchOption(msg.String()) {
+		return m, nil
+	}
+
+	if m.replaceFocus == 0 {
+		m.replaceFind, cmd = m.replaceFind.Update(msg)
+	} else {
+		m.replaceWith, cmd = m.replaceWith.Update(msg)
+	}
+	return m, cmd
+}
+
+// startReplace finds every cell to change and either replaces them all or
+// steps through them asking for confirmation
+func (m *Model) startReplace(all bool) {
+	find := m.replaceFind.Value()
+	if find == "" {
+		m.status = models.StatusMsg{Message: "Nothing to find", Type: models.StatusWarning}
+		return
+	}
+
+	re, err := loader.CompileSearch(find, m.searchOptions)
+	if err != nil {
+		m.status = models.StatusMsg{Message: err.Error(), Type: models.StatusError}
+		return
+	}
+	if re.MatchString("") {
+		m.status = models.StatusMsg{Message: "Pattern matches empty text", Type: models.StatusError}
+		return
+	}
+
+	matches, ok := m.replaceTargets(re)
+	if !ok {
+		return
+	}
+	if len(matches) == 0 {
+		m.status = models.StatusMsg{Message: "No matches to replace", Type: models.StatusWarning}
+		return
+	}
+
+	m.replaceRe = re
+	m.replaceTemplate = m.replaceWith.Value()
+	if m.searchOptions.Regex {
+		// A $ the user typed is literal, so only \1 style references expand
+		m.replaceTemplate = strings.ReplaceAll(m.replaceTemplate, "$", "$$")
+		m.replaceTemplate = backrefPattern.ReplaceAllString(m.replaceTemplate, "$${$1}")
+	}
+	m.replaceMatches = matches
+	m.replaceIndex = 0
+	m.replaceStep = m.newStep("replace", stepCells)
+	m.replaceCount = 0
+
+	// Highlight the cells to change like search results
+	m.searchQuery = find
+	m.searchResults = matches
+	m.searchIndex = 0
+
+	if all {
+		m.replaceRemaining()
+		return
+	}
+	m.mode = models.ModeReplaceConfirm
+	m.jumpToSearchResult()
+}
+
+// replaceTargets lists the cells in the search scope that the pattern would
+// change: values of plain cells and, when enabled, formula text. Read-only
+// sheets and rows hidden by filters are skipped.
+func (m *Model) replaceTargets(re *regexp.Regexp) ([]models.SearchResult, bool) {
+	opts := m.searchOptions
+
+	sheets := []int{m.currentSheet}
+	if opts.Scope == models.ScopeWorkbook {
+		sheets = nil
+		for i, sheet := range m.sheets {
+			if !sheet.ReadOnly {
+				sheets = append(sheets, i)
+			}
+		}
+	}
+	if !m.loadAllRows(sheets...) {
+		return nil, false
+	}
+
+	startRow, startCol, endRow, endCol := 0, 0, -1, -1
+	switch opts.Scope {
+	case models.ScopeColumn:
+		startCol, endCol = m.cursorCol, m.cursorCol
+	case models.ScopeSelection:
+		if !m.isSelecting {
+			m.status = models.StatusMsg{Message: "No selection - use V to select a range", Type: models.StatusWarning}
+			return nil, false
+		}
+		startRow, startCol, endRow, endCol = m.selectionBounds()
+	}
+
+	var matches []models.SearchResult
+	for _, idx := range sheets {
+		view := m.rowView
+		if idx != m.currentSheet {
+			view = m.sheetRowView(idx)
+		}
+		for r, row := range m.sheets[idx].Rows {
+			if (endRow >= 0 && (r < startRow || r > endRow)) || !rowShown(view, r) {
+				continue
+			}
+			for c, cell := range row {
+				if (endCol >= 0 && (c < startCol || c > endCol)) || m.sheets[idx].HiddenCols[c] {
+					continue
+				}
+				text := cell.Value
+				if cell.Formula != "" {
+					if !m.replaceFormulas {
+						continue
+					}
+					text = cell.Formula
+				}
+				if re.MatchString(text) {
+					matches = append(matches, models.SearchResult{Sheet: idx, Row: r, Col: c})
+				}
+			}
+		}
+	}
+	return matches, true
+}
+
+// replaceCell rewrites one matched cell and records the change
+func (m *Model) replaceCell(target models.SearchResult) {
+	cell := &m.sheets[target.Sheet].Rows[target.Row][target.Col]
+	before := *cell
+
+	text := &cell.Value
+	if cell.Formula != "" {
+		text = &cell.Formula
+	}
+	count := len(m.replaceRe.FindAllStringIndex(*text, -1))
+	if m.searchOptions.Regex {
+		*text = m.replaceRe.ReplaceAllString(*text, m.replaceTemplate)
+	} else {
+		*text = m.replaceRe.ReplaceAllLiteralString(*text, m.replaceTemplate)
+	}
+	cell.Row, cell.Col = target.Row, target.Col
+
+	m.replaceCount += count
+	m.replaceStep.changes = append(m.replaceStep.changes, cellChange{
+		sheet: target.Sheet, row: target.Row, col: target.Col,
+		before: before,
+	})
+}
+
+// replaceRemaining replaces the current match and every one after it
+func (m *Model) replaceRemaining() {
+	for ; m.replaceIndex < len(m.replaceMatches); m.replaceIndex++ {
+		m.replaceCell(m.replaceMatches[m.replaceIndex])
+	}
+	m.finishReplace()
+}
+
+// finishReplace recalculates changed sheets, records the replacement as one
+// undo step and reports what changed
+func (m *Model) finishReplace() {
+	m.mode = models.ModeNormal
+	m.searchResults = nil
+	m.searchIndex = 0
+
+	step := m.replaceStep
+	m.replaceStep = editStep{}
+	m.replaceMatches = nil
+
+	touched := make(map[int]bool)
+	for _, change := range step.changes {
+		if !touched[change.sheet] {
+			touched[change.sheet] = true
+			m.recalculateSheet(change.sheet)
+		}
+	}
+	changes := len(step.changes)
+	if !m.recordStep(step) {
+		m.status = models.StatusMsg{Message: "No replacements made", Type: models.StatusInfo}
+		return
+	}
+	m.applyFilters()
+
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Replaced %d occurrence(s) in %d cell(s)", m.replaceCount, changes),
+		Type:    models.StatusSuccess,
+	}
+	if len(touched) > 1 {
+		m.status.Message += fmt.Sprintf(" across %d sheets", len(touched))
+	}
+}
+
+// updateReplaceConfirm handles y/n/a/q while stepping through matches
+func (m Model) updateReplaceConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch strings.ToLower(msg.String()) {
+	case "y":
+		target := m.replaceMatches[m.replaceIndex]
+		m.replaceCell(target)
+		m.recalculateSheet(target.Sheet)
+		m.replaceIndex++
+	case "n":
+		m.replaceIndex++
+	case "a":
+		m.replaceRemaining()
+		return m, nil
+	case "q", "esc":
+		m.finishReplace()
+		return m, nil
+	default:
+		return m, nil
+	}
+
+	if m.replaceIndex >= len(m.replaceMatches) {
+		m.finishReplace()
+		return m, nil
+	}
+	m.searchIndex = m.replaceIndex
+	m.jumpToSearchResult()
+	return m, nil
+}
+
+// replacePrompt describes the pending match for the confirm bar
+func (m Model) replacePrompt() string {
+	return fmt.Sprintf("Replace with %q? (%d/%d)  y yes • n no • a all • q quit",
+		m.replaceWith.Value(), m.replaceIndex+1, len(m.replaceMatches))
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/loader"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// searchTargetNames and searchScopeNames label the search bar options
+var (
+	searchTargetNames = map[models.SearchTarget]string{
+		models.SearchAll:      "values+formulas",
+		models.SearchValues:   "values",
+		models.SearchFormulas: "formulas",
+	}
+	searchScopeNames = map[models.SearchScope]string{
+		models.ScopeSheet:     "sheet",
+		models.ScopeColumn:    "column",
+		models.ScopeSelection: "selection",
+		models.ScopeWorkbook:  "all sheets",
+	}
+)
+
+// toggleSearchOption applies a search bar option key, reporting whether the
+// key was one
+func (m *Model) toggleSearchOption(key string) bool {
+	opts := &m.searchOptions
+	switch key {
+	case "alt+r":
+		opts.Regex = !opts.Regex
+	case "alt+c":
+		opts.MatchCase = !opts.MatchCase
+	case "alt+w":
+		opts.WholeCell = !opts.WholeCell
+	case "alt+f":
+		opts.Target = (opts.Target + 1) % 3
+	case "alt+s":
+		opts.Scope = (opts.Scope + 1) % 4
+	default:
+		return false
+	}
+	return true
+}
+
+// searchOptionTags returns a label for each search option, with whether it
+// is on. Target and scope are only on when they differ from the default.
+func (m Model) searchOptionTags() ([]string, []bool) {
+	opts := m.searchOptions
+	tags := []string{"regex", "case", "whole", searchTargetNames[opts.Target], searchScopeNames[opts.Scope]}
+	on := []bool{opts.Regex, opts.MatchCase, opts.WholeCell, opts.Target != models.SearchAll, opts.Scope != models.ScopeSheet}
+	return tags, on
+}
+
+// runSearch finds term in the cells covered by the search scope, skipping
+// rows hidden by filters
+func (m *Model) runSearch(term string) {
+	opts := m.searchOptions
+
+	sheets := []int{m.currentSheet}
+	if opts.Scope == models.ScopeWorkbook {
+		sheets = m.allSheetIndexes()
+	}
+	if !m.loadAllRows(sheets...) {
+		return
+	}
+
+	// Bounds of the cells searched on the current sheet
+	startRow, startCol, endRow, endCol := 0, 0, -1, -1
+	switch opts.Scope {
+	case models.ScopeColumn:
+		startCol, endCol = m.cursorCol, m.cursorCol
+	case models.ScopeSelection:
+		if !m.isSelecting {
+			m.status = models.StatusMsg{Message: "No selection - use V to select a range", Type: models.StatusWarning}
+			return
+		}
+		startRow, startCol, endRow, endCol = m.selectionBounds()
+	}
+
+	var results []models.SearchResult
+	for _, idx := range sheets {
+		cells, err := loader.SearchSheet(m.sheets[idx], term, opts)
+		if err != nil {
+			m.status = models.StatusMsg{Message: err.Error(), Type: models.StatusError}
+			return
+		}
+
+		view := m.rowView
+		if idx != m.currentSheet {
+			view = m.sheetRowView(idx)
+		}
+		for _, cell := range cells {
+			if endRow >= 0 && (cell.Row < startRow || cell.Row > endRow) {
+				continue
+			}
+			if endCol >= 0 && (cell.Col < startCol || cell.Col > endCol) {
+				continue
+			}
+			if rowShown(view, cell.Row) && !m.sheets[idx].HiddenCols[cell.Col] {
+				results = append(results, models.SearchResult{Sheet: idx, Row: cell.Row, Col: cell.Col})
+			}
+		}
+	}
+
+	m.searchQuery = term
+	m.searchResults = results
+	m.searchIndex = 0
+
+	if len(m.searchResults) == 0 {
+		m.status = models.StatusMsg{Message: "No results found", Type: models.StatusWarning}
+		return
+	}
+	m.jumpToSearchResult()
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Found %d results", len(m.searchResults)),
+		Type:    models.StatusSuccess,
+	}
+	if sheetsHit := m.resultSheets(); sheetsHit > 1 {
+		m.status.Message += fmt.Sprintf(" in %d sheets", sheetsHit)
+	}
+}
+
+// resultSheets returns the number of sheets with search results
+func (m Model) resultSheets() int {
+	seen := make(map[int]bool)
+	for _, result := range m.searchResults {
+		seen[result.Sheet] = true
+	}
+	return len(seen)
+}
+
+// jumpToSearchResult jumps to the current search result, switching sheet if
+// it is on another one
+func (m *Model) jumpToSearchResult() {
+	if len(m.searchResults) == 0 {
+		return
+	}
+
+	result := m.searchResults[m.searchIndex]
+	if result.Sheet != m.currentSheet && result.Sheet < len(m.sheets) {
+		m.currentSheet = result.Sheet
+		m.applyFilters()
+	}
+	m.cursorRow = result.Row
+	m.cursorCol = result.Col
+	m.centerView()
+}
+
+// isSearchMatch checks if a cell of the current sheet is a search match
+func (m *Model) isSearchMatch(row, col int) bool {
+	for _, result := range m.searchResults {
+		if result.Sheet == m.currentSheet && result.Row == row && result.Col == col {
+			return true
+		}
+	}
+	return false
+}
+
+// searchResultLabel describes the current result, naming its sheet when the
+// results span several sheets
+func (m Model) searchResultLabel() string {
+	label := fmt.Sprintf("Match %d/%d", m.searchIndex+1, len(m.searchResults))
+	if m.resultSheets() > 1 {
+		label += " • " + strings.TrimSpace(m.sheets[m.currentSheet].Name)
+	}
+	return label
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// selectedRange returns the bounds of the selection clipped to the sheet, or
+// of the cursor cell when nothing is selected
+func (m Model) selectedRange() (startRow, startCol, endRow, endCol int) {
+	if !m.isSelecting {
+		return m.cursorRow, m.cursorCol, m.cursorRow, m.cursorCol
+	}
+	sheet := m.sheets[m.currentSheet]
+	startRow, startCol, endRow, endCol = m.selectionBounds()
+	return startRow, startCol, ui.Min(endRow, ui.Max(0, sheet.MaxRows-1)), ui.Min(endCol, ui.Max(0, sheet.MaxCols-1))
+}
+
+// rangeText returns a block of cells as tab-separated lines. Formulas are
+// copied as =formula when formulas are shown.
+func (m Model) rangeText(startRow, startCol, endRow, endCol int) string {
+	sheet := m.sheets[m.currentSheet]
+	lines := make([]string, 0, endRow-startRow+1)
+	for row := startRow; row <= endRow; row++ {
+		values := make([]string, 0, endCol-startCol+1)
+		for col := startCol; col <= endCol; col++ {
+			cell := cellOrEmpty(&sheet, row, col)
+			value := cell.Value
+			if m.showFormulas && cell.Formula != "" {
+				value = "=" + cell.Formula
+			}
+			values = append(values, value)
+		}
+		lines = append(lines, strings.Join(values, "\t"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cutSelection yanks the selection, or the cursor cell, and clears it
+func (m *Model) cutSelection() {
+	if !m.canEdit() || !m.yank(m.selectedRange()) {
+		return
+	}
+	yanked := m.status.Message
+	if m.clearRange("cut") {
+		m.status = models.StatusMsg{Message: strings.Replace(yanked, "Yanked", "Cut", 1), Type: models.StatusSuccess}
+	}
+}
+
+// clearRange empties the selection, or the cursor cell, as one undo step and
+// reports whether anything changed
+func (m *Model) clearRange(label string) bool {
+	if !m.canEdit() {
+		return false
+	}
+	sheet := &m.sheets[m.currentSheet]
+	startRow, startCol, endRow, endCol := m.selectedRange()
+	endRow = ui.Min(endRow, len(sheet.Rows)-1)
+
+	step := m.newStep(label, stepCells)
+	step.snapshot(sheet, startRow, startCol, endRow, endCol)
+	for row := startRow; row <= endRow; row++ {
+		for col := startCol; col <= endCol && col < len(sheet.Rows[row]); col++ {
+			sheet.Rows[row][col].Value = ""
+			sheet.Rows[row][col].Formula = ""
+		}
+	}
+	m.recalculateFormulas()
+	if !m.recordStep(step) {
+		m.status = models.StatusMsg{Message: "Nothing to clear", Type: models.StatusInfo}
+		return false
+	}
+	return true
+}
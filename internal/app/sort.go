@@ -0,0 +1,293 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// Kinds of sort values, in the order they sort ascending. Empty cells always
+// sort last, whatever the direction.
+const (
+	sortNumber = iota
+	sortDate
+	sortText
+	sortEmpty
+)
+
+// sortDateLayouts are the date formats recognised when sorting
+var sortDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.RFC3339,
+	"2006/01/02",
+	"01/02/2006",
+	"1/2/2006",
+	"02-Jan-2006",
+	"2 Jan 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// sortValue is a cell value classified for comparison
+type sortValue struct {
+	kind int
+	num  float64
+	text string
+}
+
+// parseSortValue classifies a cell value as a number, date, text or empty
+func parseSortValue(value string) sortValue {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return sortValue{kind: sortEmpty}
+	}
+
+	number := strings.ReplaceAll(trimmed, ",", "")
+	percent := strings.HasSuffix(number, "%")
+	number = strings.TrimSuffix(number, "%")
+	if n, err := strconv.ParseFloat(number, 64); err == nil {
+		if percent {
+			n /= 100
+		}
+		return sortValue{kind: sortNumber, num: n}
+	}
+
+	for _, layout := range sortDateLayouts {
+		if t, err := time.Parse(layout, trimmed); err == nil {
+			return sortValue{kind: sortDate, num: float64(t.Unix())}
+		}
+	}
+
+	return sortValue{kind: sortText, text: strings.ToLower(trimmed)}
+}
+
+// compareSortValues returns -1, 0 or 1 as a sorts before, with or after b in
+// ascending order
+func compareSortValues(a, b sortValue) int {
+	if a.kind != b.kind {
+		if a.kind < b.kind {
+			return -1
+		}
+		return 1
+	}
+	switch {
+	case a.kind == sortText:
+		return strings.Compare(a.text, b.text)
+	case a.num < b.num:
+		return -1
+	case a.num > b.num:
+		return 1
+	}
+	return 0
+}
+
+// parseSortKeys parses a sort specification such as "B desc, A" into keys.
+// Each column may be followed by asc or desc; ascending is the default.
+func parseSortKeys(spec string) ([]models.SortKey, error) {
+	var keys []models.SortKey
+	for _, field := range strings.Fields(strings.ReplaceAll(spec, ",", " ")) {
+		switch strings.ToLower(field) {
+		case "asc", "desc":
+			if len(keys) == 0 {
+				return nil, fmt.Errorf("%s must follow a column", field)
+			}
+			keys[len(keys)-1].Desc = strings.EqualFold(field, "desc")
+			continue
+		}
+
+		col, err := ui.ParseColumn(field)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, models.SortKey{Col: col})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no sort columns given")
+	}
+	return keys, nil
+}
+
+// sortKeysString describes sort keys for status messages, e.g. "B▼, A▲"
+func sortKeysString(keys []models.SortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = ui.ColIndexToLetter(key.Col) + sortArrow(key.Desc)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortArrow returns the header indicator for a sort direction
+func sortArrow(desc bool) string {
+	if desc {
+		return "▼"
+	}
+	return "▲"
+}
+
+// sortIndicator returns the sort marker for a column header, numbered by
+// precedence when the sheet was sorted by several columns
+func (m Model) sortIndicator(col int) string {
+	keys := m.sheets[m.currentSheet].Sort
+	for i, key := range keys {
+		if key.Col == col {
+			if len(keys) > 1 {
+				return sortArrow(key.Desc) + strconv.Itoa(i+1)
+			}
+			return sortArrow(key.Desc)
+		}
+	}
+	return ""
+}
+
+// sortSheet sorts the selection, or the whole sheet, by keys. When sorting
+// the whole sheet and keepHeader is set, the header row (the first row, or
+// the filter header) and any rows above it stay in place.
+func (m *Model) sortSheet(keys []models.SortKey, keepHeader bool) {
+	if !m.canReshape() {
+		return
+	}
+
+	sheet := &m.sheets[m.currentSheet]
+	startRow, startCol, endRow, endCol := 0, 0, len(sheet.Rows)-1, sheet.MaxCols-1
+	if m.isSelecting {
+		startRow, startCol, endRow, endCol = m.selectionBounds()
+		endRow = ui.Min(endRow, len(sheet.Rows)-1)
+	} else if keepHeader {
+		startRow = sheet.FilterHeader + 1
+	}
+
+	for _, key := range keys {
+		if key.Col < startCol || key.Col > endCol {
+			m.status = models.StatusMsg{
+				Message: fmt.Sprintf("Sort column %s is outside the range being sorted", ui.ColIndexToLetter(key.Col)),
+				Type:    models.StatusWarning,
+			}
+			return
+		}
+	}
+	if endRow-startRow < 1 {
+		m.status = models.StatusMsg{Message: "Nothing to sort", Type: models.StatusInfo}
+		return
+	}
+
+	step := m.newStep("sort by "+sortKeysString(keys), stepCells)
+	step.snapshot(sheet, startRow, startCol, endRow, endCol)
+	step.keepsLayout = true
+	step.layoutBefore = layoutOf(sheet)
+	m.sortRows(startRow, endRow, startCol, endCol, keys)
+
+	sheet.Sort = keys
+	m.recalculateFormulas()
+	m.recordStep(step)
+	m.applyFilters()
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Sorted %d rows by %s", endRow-startRow+1, sortKeysString(keys)),
+		Type:    models.StatusSuccess,
+	}
+}
+
+// sortRows reorders rows startRow..endRow within columns startCol..endCol
+// using a stable sort. Formulas move with their row and have their relative
+// row references shifted by the distance moved. When whole rows are sorted
+// their heights and outline levels move with them. Hidden rows keep their
+// place and content, so a sort never changes what is hidden.
+func (m *Model) sortRows(startRow, endRow, startCol, endCol int, keys []models.SortKey) {
+	sheet := &m.sheets[m.currentSheet]
+
+	values := make(map[int][]sortValue, endRow-startRow+1)
+	order := make([]int, 0, endRow-startRow+1)
+	for row := startRow; row <= endRow; row++ {
+		if sheet.HiddenRows[row] {
+			continue
+		}
+		order = append(order, row)
+		rowValues := make([]sortValue, len(keys))
+		for i, key := range keys {
+			value := ""
+			if key.Col < len(sheet.Rows[row]) {
+				value = sheet.Rows[row][key.Col].Value
+			}
+			rowValues[i] = parseSortValue(value)
+		}
+		values[row] = rowValues
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		va, vb := values[order[a]], values[order[b]]
+		for i, key := range keys {
+			// Empty cells stay at the bottom in both directions
+			if (va[i].kind == sortEmpty) != (vb[i].kind == sortEmpty) {
+				return vb[i].kind == sortEmpty
+			}
+			c := compareSortValues(va[i], vb[i])
+			if key.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	// The shown rows in sheet order are the places the sorted rows go
+	slots := slices.Clone(order)
+	slices.Sort(slots)
+
+	// Snapshot the sorted block before writing it back
+	width := endCol - startCol + 1
+	block := make(map[int][]models.Cell, len(order))
+	for _, row := range order {
+		cells := make([]models.Cell, width)
+		for col := startCol; col <= endCol && col < len(sheet.Rows[row]); col++ {
+			cells[col-startCol] = sheet.Rows[row][col]
+		}
+		block[row] = cells
+	}
+
+	for i, source := range order {
+		target := slots[i]
+		if len(sheet.Rows[target]) <= endCol {
+			padded := make([]models.Cell, endCol+1)
+			copy(padded, sheet.Rows[target])
+			sheet.Rows[target] = padded
+		}
+		for j, cell := range block[source] {
+			cell.Row = target
+			cell.Col = startCol + j
+			if cell.Formula != "" && target != source {
+				cell.Formula = m.adjustFormulaReferences(cell.Formula, target-source, 0)
+			}
+			sheet.Rows[target][startCol+j] = cell
+		}
+	}
+
+	if startCol == 0 && endCol >= sheet.MaxCols-1 {
+		moveRowLayout(sheet.RowHeights, order, slots)
+		moveRowLayout(sheet.RowLevels, order, slots)
+	}
+}
+
+// moveRowLayout moves per-row layout from each sorted row to the slot it was
+// written to
+func moveRowLayout[V any](layout map[int]V, order, slots []int) {
+	moved := make(map[int]V, len(order))
+	for i, source := range order {
+		if value, ok := layout[source]; ok {
+			moved[slots[i]] = value
+		}
+	}
+	for _, row := range slots {
+		delete(layout, row)
+	}
+	maps.Copy(layout, moved)
+}
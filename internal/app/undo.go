@@ -0,0 +1,334 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// stepKind is the kind of edit an undo step reverts
+type stepKind int
+
+const (
+	stepCells stepKind = iota
+	stepInsertRow
+	stepDeleteRow
+	stepInsertCol
+	stepDeleteCol
+	stepLayout
+)
+
+// cellChange is the content of one cell before and after an edit
+type cellChange struct {
+	sheet, row, col int
+	before, after   models.Cell
+}
+
+// sheetLayout is the state of a sheet that is saved with it but is not cell
+// content
+type sheetLayout struct {
+	colWidths  map[int]int
+	rowHeights map[int]int
+	hiddenRows map[int]bool
+	hiddenCols map[int]bool
+	rowLevels  map[int]int
+	colLevels  map[int]int
+	freezeRows int
+	freezeCols int
+	// Rules are replaced rather than changed in place, so the slice is
+	// copied but not the rules
+	validations []models.Validation
+}
+
+// layoutOf copies the layout of a sheet
+func layoutOf(sheet *models.Sheet) sheetLayout {
+	return sheetLayout{
+		colWidths:  maps.Clone(sheet.ColWidths),
+		rowHeights: maps.Clone(sheet.RowHeights),
+		hiddenRows: maps.Clone(sheet.HiddenRows),
+		hiddenCols: maps.Clone(sheet.HiddenCols),
+		rowLevels:  maps.Clone(sheet.RowLevels),
+		colLevels:  maps.Clone(sheet.ColLevels),
+		freezeRows: sheet.FreezeRows,
+		freezeCols: sheet.FreezeCols,
+
+		validations: slices.Clone(sheet.Validations),
+	}
+}
+
+// apply gives a sheet this layout
+func (l sheetLayout) apply(sheet *models.Sheet) {
+	sheet.ColWidths = maps.Clone(l.colWidths)
+	sheet.RowHeights = maps.Clone(l.rowHeights)
+	sheet.HiddenRows = maps.Clone(l.hiddenRows)
+	sheet.HiddenCols = maps.Clone(l.hiddenCols)
+	sheet.RowLevels = maps.Clone(l.rowLevels)
+	sheet.ColLevels = maps.Clone(l.colLevels)
+	sheet.FreezeRows, sheet.FreezeCols = l.freezeRows, l.freezeCols
+	sheet.Validations = slices.Clone(l.validations)
+}
+
+// equal reports whether two layouts are the same
+func (l sheetLayout) equal(other sheetLayout) bool {
+	return maps.Equal(l.colWidths, other.colWidths) && maps.Equal(l.rowHeights, other.rowHeights) &&
+		maps.Equal(l.hiddenRows, other.hiddenRows) && maps.Equal(l.hiddenCols, other.hiddenCols) &&
+		maps.Equal(l.rowLevels, other.rowLevels) && maps.Equal(l.colLevels, other.colLevels) &&
+		l.freezeRows == other.freezeRows && l.freezeCols == other.freezeCols &&
+		slices.EqualFunc(l.validations, other.validations, sameValidation)
+}
+
+// editStep is one entry of the undo history. Cell edits keep every changed
+// cell so that a multi-cell operation is undone at once; row and column
+// steps keep what was inserted or deleted. Layout steps, and row and column
+// steps whose shifts drop the layout of a deleted row or column, keep the
+// layout of the sheet before and after.
+type editStep struct {
+	label     string
+	kind      stepKind
+	sheet     int
+	cursorRow int
+	cursorCol int
+
+	changes []cellChange
+
+	// Row or column inserted or deleted, with the cells it held. present
+	// marks the rows that had a cell in a deleted column.
+	index   int
+	cells   []models.Cell
+	present []bool
+
+	sortBefore []models.SortKey
+	sortAfter  []models.SortKey
+
+	keepsLayout  bool
+	layoutBefore sheetLayout
+	layoutAfter  sheetLayout
+
+	// Filters are changed outside the history, so a column step keeps only
+	// the filters of the deleted column, and a row step the filter header
+	filters      []models.Filter
+	filterHeader int
+}
+
+// newStep starts an undo step on the current sheet, remembering the cursor
+// to return to
+func (m Model) newStep(label string, kind stepKind) editStep {
+	sheet := m.sheets[m.currentSheet]
+	step := editStep{
+		label:      label,
+		kind:       kind,
+		sheet:      m.currentSheet,
+		cursorRow:  m.cursorRow,
+		cursorCol:  m.cursorCol,
+		sortBefore: sheet.Sort,
+	}
+	if kind != stepCells {
+		step.keepsLayout = true
+		step.layoutBefore = layoutOf(&sheet)
+	}
+	step.filterHeader = sheet.FilterHeader
+	return step
+}
+
+// snapshot records the current content of a block of cells of the step's
+// sheet before they are changed
+func (s *editStep) snapshot(sheet *models.Sheet, startRow, startCol, endRow, endCol int) {
+	for row := startRow; row <= endRow; row++ {
+		for col := startCol; col <= endCol; col++ {
+			s.changes = append(s.changes, cellChange{
+				sheet: s.sheet, row: row, col: col,
+				before: cellOrEmpty(sheet, row, col),
+			})
+		}
+	}
+}
+
+// cellOrEmpty returns the cell at row, col, or an empty cell outside the data
+func cellOrEmpty(sheet *models.Sheet, row, col int) models.Cell {
+	if row < len(sheet.Rows) && col < len(sheet.Rows[row]) {
+		return sheet.Rows[row][col]
+	}
+	return models.Cell{Row: row, Col: col}
+}
+
+// ensureCell grows the rows of a sheet so that row, col exists and returns it
+func ensureCell(sheet *models.Sheet, row, col int) *models.Cell {
+	for len(sheet.Rows) <= row {
+		sheet.Rows = append(sheet.Rows, make([]models.Cell, sheet.MaxCols))
+	}
+	if col >= len(sheet.Rows[row]) {
+		grown := make([]models.Cell, ui.Max(col+1, sheet.MaxCols))
+		copy(grown, sheet.Rows[row])
+		sheet.Rows[row] = grown
+	}
+	cell := &sheet.Rows[row][col]
+	cell.Row, cell.Col = row, col
+	return cell
+}
+
+// recordStep completes a step after its edit has been made and adds it to
+// the history. Cell steps keep only the cells that actually changed and are
+// dropped when nothing did; it reports whether anything was recorded.
+func (m *Model) recordStep(step editStep) bool {
+	if step.kind == stepCells {
+		changed := step.changes[:0]
+		for _, change := range step.changes {
+			change.after = cellOrEmpty(&m.sheets[change.sheet], change.row, change.col)
+			if change.after.Value != change.before.Value || change.after.Formula != change.before.Formula ||
+				change.after.Comment != change.before.Comment || change.after.CommentAuthor != change.before.CommentAuthor ||
+				change.after.Link != change.before.Link {
+				changed = append(changed, change)
+			}
+		}
+		step.changes = changed
+		if len(changed) == 0 {
+			return false
+		}
+	}
+	if step.keepsLayout {
+		step.layoutAfter = layoutOf(&m.sheets[step.sheet])
+		if step.kind == stepLayout && step.layoutAfter.equal(step.layoutBefore) {
+			return false
+		}
+	}
+	step.sortAfter = m.sheets[step.sheet].Sort
+
+	// A saved state only reachable by redo is lost once history branches
+	if m.savedStep > len(m.undoStack) {
+		m.savedStep = -1
+	}
+	m.undoStack = append(m.undoStack, step)
+	m.redoStack = nil
+	m.syncModified()
+	return true
+}
+
+// syncModified sets the modified flag from the position in the history
+// relative to the last save
+func (m *Model) syncModified() {
+	m.modified = len(m.undoStack) != m.savedStep
+}
+
+// markSaved records the current position in the history as saved
+func (m *Model) markSaved() {
+	m.savedStep = len(m.undoStack)
+	m.syncModified()
+}
+
+// undo reverts the last edit
+func (m *Model) undo() {
+	if len(m.undoStack) == 0 {
+		m.status = models.StatusMsg{Message: "Nothing to undo", Type: models.StatusInfo}
+		return
+	}
+	step := m.undoStack[len(m.undoStack)-1]
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+	m.redoStack = append(m.redoStack, step)
+
+	m.applyStep(step, true)
+	m.status = models.StatusMsg{Message: "Undo: " + stepDescription(step), Type: models.StatusSuccess}
+}
+
+// redo applies the last undone edit again
+func (m *Model) redo() {
+	if len(m.redoStack) == 0 {
+		m.status = models.StatusMsg{Message: "Nothing to redo", Type: models.StatusInfo}
+		return
+	}
+	step := m.redoStack[len(m.redoStack)-1]
+	m.redoStack = m.redoStack[:len(m.redoStack)-1]
+	m.undoStack = append(m.undoStack, step)
+
+	m.applyStep(step, false)
+	m.status = models.StatusMsg{Message: "Redo: " + stepDescription(step), Type: models.StatusSuccess}
+}
+
+// applyStep reverts a step, or applies it again when revert is false, and
+// returns the cursor to where the edit was made
+func (m *Model) applyStep(step editStep, revert bool) {
+	if step.sheet >= len(m.sheets) {
+		return
+	}
+	sheet := &m.sheets[step.sheet]
+
+	// Inserting and deleting undo each other
+	kind := step.kind
+	if revert {
+		switch kind {
+		case stepInsertRow:
+			kind = stepDeleteRow
+		case stepDeleteRow:
+			kind = stepInsertRow
+		case stepInsertCol:
+			kind = stepDeleteCol
+		case stepDeleteCol:
+			kind = stepInsertCol
+		}
+	}
+
+	touched := map[int]bool{step.sheet: true}
+	switch kind {
+	case stepCells:
+		for i := range step.changes {
+			change := step.changes[len(step.changes)-1-i]
+			content := change.before
+			if !revert {
+				change = step.changes[i]
+				content = change.after
+			}
+			cell := ensureCell(&m.sheets[change.sheet], change.row, change.col)
+			cell.Value, cell.Formula = content.Value, content.Formula
+			cell.Comment, cell.CommentAuthor = content.Comment, content.CommentAuthor
+			cell.Link = content.Link
+			touched[change.sheet] = true
+		}
+	case stepInsertRow:
+		insertRowAt(sheet, step.index, step.cells)
+	case stepDeleteRow:
+		removeRow(sheet, step.index)
+	case stepInsertCol:
+		insertColumnAt(sheet, step.index, step.cells, step.present)
+	case stepDeleteCol:
+		removeColumn(sheet, step.index)
+	}
+	if step.keepsLayout {
+		if revert {
+			step.layoutBefore.apply(sheet)
+		} else {
+			step.layoutAfter.apply(sheet)
+		}
+	}
+	if revert && (step.kind == stepDeleteRow || step.kind == stepInsertRow) {
+		sheet.FilterHeader = step.filterHeader
+	}
+	if revert && step.kind == stepDeleteCol {
+		sheet.Filters = append(slices.Clone(sheet.Filters), step.filters...)
+	}
+
+	if revert {
+		sheet.Sort = step.sortBefore
+	} else {
+		sheet.Sort = step.sortAfter
+	}
+	for idx := range touched {
+		m.recalculateSheet(idx)
+	}
+
+	m.currentSheet = step.sheet
+	m.cursorRow = ui.Min(step.cursorRow, ui.Max(0, sheet.MaxRows-1))
+	m.cursorCol = ui.Min(step.cursorCol, ui.Max(0, sheet.MaxCols-1))
+	m.applyFilters()
+	m.adjustViewport()
+	m.syncModified()
+}
+
+// stepDescription names a step for status messages
+func stepDescription(step editStep) string {
+	if step.kind == stepCells && len(step.changes) > 1 {
+		return fmt.Sprintf("%s (%d cells)", step.label, len(step.changes))
+	}
+	return step.label
+}
@@ -5,10 +5,8 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/CodeOne45/vex-tui/internal/loader"
 	"github.com/CodeOne45/vex-tui/internal/ui"
 	"github.com/CodeOne45/vex-tui/pkg/models"
-	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -26,6 +24,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.help.Width = msg.Width
+		if len(m.sheets) > 0 {
+			m.adjustViewport()
+		}
+		return m, nil
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
+	case linkOpenedMsg:
+		m.openedLink(msg)
 		return m, nil
 
 	case tea.KeyMsg:
@@ -48,6 +56,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateEdit(msg)
 		case models.ModeSaveAs:
 			return m.updateSaveAs(msg)
+		case models.ModeBuffers:
+			return m.updateBuffers(msg)
+		case models.ModeCommand:
+			return m.updateCommand(msg)
+		case models.ModeFilter:
+			return m.updateFilter(msg)
+		case models.ModeReplace:
+			return m.updateReplace(msg)
+		case models.ModeReplaceConfirm:
+			return m.updateReplaceConfirm(msg)
 		default:
 			return m.updateNormal(msg)
 		}
@@ -67,62 +85,137 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	sheet := m.sheets[m.currentSheet]
 
+	// A " prefix names the register for the next yank, cut or put
+	if m.awaitingRegister {
+		m.selectRegister(msg.String())
+		return m, nil
+	}
+
+	// A Ctrl+W prefix splits, moves between or closes panes
+	if m.awaitingWindow {
+		m.windowCommand(msg.String())
+		return m, nil
+	}
+
 	switch {
+	case key.Matches(msg, m.keys.Register):
+		m.quitConfirm = false
+		m.awaitingRegister = true
+		m.status = models.StatusMsg{Message: "\" (register a-z, \" or +)", Type: models.StatusInfo}
+
 	case key.Matches(msg, m.keys.Quit):
-		if m.modified && !m.quitConfirm {
+		if dirty := m.modifiedBuffers(); dirty > 0 && !m.quitConfirm {
 			m.quitConfirm = true
+			message := "Unsaved changes! Press q again to quit without saving, or Ctrl+S to save"
+			if len(m.buffers) > 1 {
+				message = fmt.Sprintf("%d of %d files have unsaved changes! Press q again to quit without saving, or B to review", dirty, len(m.buffers))
+			}
 			m.status = models.StatusMsg{
-				Message: "Unsaved changes! Press q again to quit without saving, or Ctrl+S to save",
+				Message: message,
 				Type:    models.StatusWarning,
 			}
 			return m, nil
 		}
 		return m, tea.Quit
 
+	case key.Matches(msg, m.keys.Comment):
+		m.quitConfirm = false
+		return m, m.editComment()
+
+	case key.Matches(msg, m.keys.Collapse):
+		m.quitConfirm = false
+		m.collapseGroup(false)
+
+	case key.Matches(msg, m.keys.Expand):
+		m.quitConfirm = false
+		m.expandGroup(false)
+
+	case key.Matches(msg, m.keys.OutlineLevel):
+		m.quitConfirm = false
+		level, _ := strconv.Atoi(strings.TrimPrefix(msg.String(), "alt+"))
+		m.showOutlineLevel(level, false)
+
+	case key.Matches(msg, m.keys.Window):
+		m.quitConfirm = false
+		m.awaitingWindow = true
+		m.status = models.StatusMsg{Message: "^w (s split, v vsplit, w next, W prev, q close, o only)", Type: models.StatusInfo}
+
+	case key.Matches(msg, m.keys.NextBuffer):
+		m.cycleBuffer(1)
+
+	case key.Matches(msg, m.keys.PrevBuffer):
+		m.cycleBuffer(-1)
+
+	case key.Matches(msg, m.keys.BufferList):
+		m.quitConfirm = false
+		m.openBufferList()
+
+	case key.Matches(msg, m.keys.SortAsc):
+		m.quitConfirm = false
+		m.sortSheet([]models.SortKey{{Col: m.cursorCol}}, true)
+
+	case key.Matches(msg, m.keys.SortDesc):
+		m.quitConfirm = false
+		m.sortSheet([]models.SortKey{{Col: m.cursorCol, Desc: true}}, true)
+
+	case key.Matches(msg, m.keys.Filter):
+		m.quitConfirm = false
+		m.openFilter()
+		return m, textinput.Blink
+
+	case key.Matches(msg, m.keys.Freeze):
+		m.quitConfirm = false
+		m.toggleFreeze()
+
+	case key.Matches(msg, m.keys.Replace):
+		m.quitConfirm = false
+		m.openReplace()
+		return m, textinput.Blink
+
+	case key.Matches(msg, m.keys.Undo):
+		m.quitConfirm = false
+		m.undo()
+
+	case key.Matches(msg, m.keys.Redo):
+		m.quitConfirm = false
+		m.redo()
+
+	case key.Matches(msg, m.keys.Command):
+		m.quitConfirm = false
+		m.mode = models.ModeCommand
+		m.commandInput.SetValue("")
+		m.commandInput.Focus()
+		return m, textinput.Blink
+
 	case key.Matches(msg, m.keys.Up):
 		m.quitConfirm = false
-		if m.cursorRow > 0 {
-			m.cursorRow--
-			m.adjustViewport()
-		}
+		m.moveCursorRows(-1)
 
 	case key.Matches(msg, m.keys.Down):
 		m.quitConfirm = false
-		if m.cursorRow < sheet.MaxRows-1 {
-			m.cursorRow++
-			m.adjustViewport()
-		}
+		m.moveCursorRows(1)
 
 	case key.Matches(msg, m.keys.Left):
 		m.quitConfirm = false
-		if m.cursorCol > 0 {
-			m.cursorCol--
-			m.adjustViewport()
-		}
+		m.moveCursorCols(-1)
 
 	case key.Matches(msg, m.keys.Right):
 		m.quitConfirm = false
-		if m.cursorCol < sheet.MaxCols-1 {
-			m.cursorCol++
-			m.adjustViewport()
-		}
+		m.moveCursorCols(1)
 
 	case key.Matches(msg, m.keys.PageDown):
 		m.quitConfirm = false
-		visibleRows := ui.Max(1, m.height-9)
-		m.cursorRow = ui.Min(m.cursorRow+visibleRows, sheet.MaxRows-1)
-		m.adjustViewport()
+		m.moveCursorRows(m.pageRows())
 
 	case key.Matches(msg, m.keys.PageUp):
 		m.quitConfirm = false
-		visibleRows := ui.Max(1, m.height-9)
-		m.cursorRow = ui.Max(m.cursorRow-visibleRows, 0)
-		m.adjustViewport()
+		m.moveCursorRows(-m.pageRows())
 
 	case key.Matches(msg, m.keys.Home):
 		m.quitConfirm = false
 		m.cursorCol = 0
 		m.offsetCol = 0
+		m.adjustViewport()
 
 	case key.Matches(msg, m.keys.End):
 		m.quitConfirm = false
@@ -133,6 +226,7 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.quitConfirm = false
 		m.cursorCol = 0
 		m.offsetCol = 0
+		m.adjustViewport()
 
 	case key.Matches(msg, m.keys.LastCol):
 		m.quitConfirm = false
@@ -175,7 +269,7 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.searchIndex = (m.searchIndex + 1) % len(m.searchResults)
 			m.jumpToSearchResult()
 			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Match %d/%d", m.searchIndex+1, len(m.searchResults)),
+				Message: m.searchResultLabel(),
 				Type:    models.StatusInfo,
 			}
 		}
@@ -186,14 +280,17 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.searchIndex = (m.searchIndex - 1 + len(m.searchResults)) % len(m.searchResults)
 			m.jumpToSearchResult()
 			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Match %d/%d", m.searchIndex+1, len(m.searchResults)),
+				Message: m.searchResultLabel(),
 				Type:    models.StatusInfo,
 			}
 		}
 
 	case key.Matches(msg, m.keys.ClearSearch):
 		m.quitConfirm = false
-		if m.searchQuery != "" {
+		if m.isSelecting {
+			m.isSelecting = false
+			m.status = models.StatusMsg{Message: "Selection cleared", Type: models.StatusInfo}
+		} else if m.searchQuery != "" {
 			m.searchQuery = ""
 			m.searchResults = nil
 			m.searchIndex = 0
@@ -221,10 +318,18 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.status = models.StatusMsg{Message: "Showing values", Type: models.StatusInfo}
 		}
 
+	case key.Matches(msg, m.keys.ToggleWrap):
+		m.quitConfirm = false
+		m.toggleWrap()
+
 	case key.Matches(msg, m.keys.Copy):
 		m.quitConfirm = false
 		m.copyCell()
 
+	case key.Matches(msg, m.keys.Cut):
+		m.quitConfirm = false
+		m.cutSelection()
+
 	case key.Matches(msg, m.keys.CopyRow):
 		m.quitConfirm = false
 		m.copyRow()
@@ -232,6 +337,10 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Export):
 		m.quitConfirm = false
 		m.mode = models.ModeExport
+		m.exportScope = exportScopeSheet
+		if m.isSelecting {
+			m.exportScope = exportScopeSelection
+		}
 		m.exportInput.Focus()
 		m.exportInput.SetValue("")
 		return m, textinput.Blink
@@ -266,7 +375,7 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		} else {
 			m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
 			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Selected %dx%d range - Press v to visualize or Ctrl+A to apply formula",
+				Message: fmt.Sprintf("Selected %dx%d range - c copy • X cut • x clear • p paste • ^j/^l fill • v chart",
 					abs(m.selectEnd[0]-m.selectStart[0])+1,
 					abs(m.selectEnd[1]-m.selectStart[1])+1),
 				Type: models.StatusSuccess,
@@ -306,12 +415,13 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Save):
 		m.quitConfirm = false
 		m.saveFile()
+		if m.mode == models.ModeSaveAs {
+			return m, textinput.Blink
+		}
 
 	case key.Matches(msg, m.keys.SaveAs):
 		m.quitConfirm = false
-		m.mode = models.ModeSaveAs
-		m.saveAsInput.Focus()
-		m.saveAsInput.SetValue(m.filename)
+		m.openSaveAs()
 		return m, textinput.Blink
 
 	case key.Matches(msg, m.keys.FillDown):
@@ -326,6 +436,11 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.quitConfirm = false
 		m.applyFormulaToRange()
 
+	case key.Matches(msg, m.keys.AutoFit):
+		m.quitConfirm = false
+		_, startCol, _, endCol := m.selectedRange()
+		m.autoFit(startCol, endCol)
+
 	case key.Matches(msg, m.keys.ColWidthInc):
 		m.quitConfirm = false
 		if sheet.ColWidths == nil {
@@ -337,12 +452,15 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			width = ui.MinCellWidth
 		}
 		if width < ui.MaxCellWidth {
+			step := m.newStep("resize column "+ui.ColIndexToLetter(m.cursorCol), stepLayout)
 			sheet.ColWidths[m.cursorCol] = width + 1
+			m.recordStep(step)
 			m.status = models.StatusMsg{
 				Message: fmt.Sprintf("Width: %d", width+1),
 				Type:    models.StatusInfo,
 			}
 		}
+		m.adjustViewport()
 
 	case key.Matches(msg, m.keys.ColWidthDec):
 		m.quitConfirm = false
@@ -355,12 +473,15 @@ func (m Model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			width = ui.MinCellWidth
 		}
 		if width > 2 {
+			step := m.newStep("resize column "+ui.ColIndexToLetter(m.cursorCol), stepLayout)
 			sheet.ColWidths[m.cursorCol] = width - 1
+			m.recordStep(step)
 			m.status = models.StatusMsg{
 				Message: fmt.Sprintf("Width: %d", width-1),
 				Type:    models.StatusInfo,
 			}
 		}
+		m.adjustViewport()
 	}
 
 	return m, nil
@@ -379,25 +500,17 @@ func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyEnter:
 		term := strings.TrimSpace(m.searchInput.Value())
 		if term != "" {
-			m.searchQuery = term
-			sheet := m.sheets[m.currentSheet]
-			m.searchResults = loader.SearchSheet(sheet, term)
-			m.searchIndex = 0
-			if len(m.searchResults) > 0 {
-				m.jumpToSearchResult()
-				m.status = models.StatusMsg{
-					Message: fmt.Sprintf("Found %d results", len(m.searchResults)),
-					Type:    models.StatusSuccess,
-				}
-			} else {
-				m.status = models.StatusMsg{Message: "No results found", Type: models.StatusWarning}
-			}
+			m.runSearch(term)
 		}
 		m.mode = models.ModeNormal
 		m.searchInput.Blur()
 		return m, nil
 	}
 
+	if m.toggleSearchOption(msg.String()) {
+		return m, nil
+	}
+
 	m.searchInput, cmd = m.searchInput.Update(msg)
 	return m, cmd
 }
@@ -407,6 +520,10 @@ func (m Model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if msg.Type == tea.KeyEscape || msg.Type == tea.KeyEnter || msg.String() == "q" {
 		m.mode = models.ModeNormal
 	}
+	if msg.String() == "o" && m.cursorLink() != "" {
+		m.mode = models.ModeNormal
+		return m, m.followLink()
+	}
 	return m, nil
 }
 
@@ -452,6 +569,14 @@ func (m Model) updateExport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = models.ModeNormal
 		m.exportInput.Blur()
 		return m, nil
+
+	case tea.KeyTab:
+		m.exportScope = m.nextExportScope(1)
+		return m, nil
+
+	case tea.KeyShiftTab:
+		m.exportScope = m.nextExportScope(-1)
+		return m, nil
 	}
 
 	m.exportInput, cmd = m.exportInput.Update(msg)
@@ -487,18 +612,6 @@ func (m Model) updateTheme(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// jumpToSearchResult jumps to the current search result
-func (m *Model) jumpToSearchResult() {
-	if len(m.searchResults) == 0 {
-		return
-	}
-
-	result := m.searchResults[m.searchIndex]
-	m.cursorRow = result.Row
-	m.cursorCol = result.Col
-	m.centerView()
-}
-
 // jumpToCell jumps to a specific cell based on user input
 func (m *Model) jumpToCell(input string) {
 	sheet := m.sheets[m.currentSheet]
@@ -568,74 +681,16 @@ func (m *Model) jumpToCell(input string) {
 	m.status = models.StatusMsg{Message: "Invalid cell reference", Type: models.StatusError}
 }
 
-// copyCell copies the current cell to clipboard
+// copyCell yanks the current cell, or the selection
 func (m *Model) copyCell() {
-	sheet := m.sheets[m.currentSheet]
-	if m.cursorRow < len(sheet.Rows) && m.cursorCol < len(sheet.Rows[m.cursorRow]) {
-		cell := sheet.Rows[m.cursorRow][m.cursorCol]
-		value := cell.Value
-		if m.showFormulas && cell.Formula != "" {
-			value = "=" + cell.Formula
-		}
-		if err := clipboard.WriteAll(value); err != nil {
-			m.status = models.StatusMsg{Message: "Failed to copy", Type: models.StatusError}
-		} else {
-			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Copied: %s", ui.Truncate(value, 30)),
-				Type:    models.StatusSuccess,
-			}
-		}
-	}
+	m.yank(m.selectedRange())
 }
 
-// copyRow copies the entire current row to clipboard
+// copyRow yanks the entire current row
 func (m *Model) copyRow() {
 	sheet := m.sheets[m.currentSheet]
-	if m.cursorRow < len(sheet.Rows) {
-		row := sheet.Rows[m.cursorRow]
-		values := make([]string, 0, len(row))
-		for _, cell := range row {
-			values = append(values, cell.Value)
-		}
-		rowText := strings.Join(values, "\t")
-		if err := clipboard.WriteAll(rowText); err != nil {
-			m.status = models.StatusMsg{Message: "Failed to copy row", Type: models.StatusError}
-		} else {
-			m.status = models.StatusMsg{
-				Message: fmt.Sprintf("Copied row %d (%d cells)", m.cursorRow+1, len(values)),
-				Type:    models.StatusSuccess,
-			}
-		}
-	}
-}
-
-// exportSheet exports the current sheet to a file
-func (m *Model) exportSheet(filename string) {
-	sheet := m.sheets[m.currentSheet]
-	var err error
-
-	if strings.HasSuffix(strings.ToLower(filename), ".csv") {
-		err = loader.ExportToCSV(sheet, filename)
-	} else if strings.HasSuffix(strings.ToLower(filename), ".json") {
-		err = loader.ExportToJSON(sheet, filename)
-	} else {
-		m.status = models.StatusMsg{
-			Message: "Use .csv or .json extension",
-			Type:    models.StatusError,
-		}
-		return
-	}
-
-	if err != nil {
-		m.status = models.StatusMsg{
-			Message: fmt.Sprintf("Export failed: %v", err),
-			Type:    models.StatusError,
-		}
-	} else {
-		m.status = models.StatusMsg{
-			Message: fmt.Sprintf("✓ Exported to %s", filename),
-			Type:    models.StatusSuccess,
-		}
+	if m.cursorRow < sheet.MaxRows && sheet.MaxCols > 0 {
+		m.yank(m.cursorRow, 0, m.cursorRow, sheet.MaxCols-1)
 	}
 }
 
@@ -658,33 +713,19 @@ func (m Model) updateChart(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // updateSelectRange handles range selection mode
 func (m Model) updateSelectRange(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	sheet := m.sheets[m.currentSheet]
-
 	switch {
 	case key.Matches(msg, m.keys.Up):
-		if m.cursorRow > 0 {
-			m.cursorRow--
-			m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
-			m.adjustViewport()
-		}
+		m.moveCursorRows(-1)
+		m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
 	case key.Matches(msg, m.keys.Down):
-		if m.cursorRow < sheet.MaxRows-1 {
-			m.cursorRow++
-			m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
-			m.adjustViewport()
-		}
+		m.moveCursorRows(1)
+		m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
 	case key.Matches(msg, m.keys.Left):
-		if m.cursorCol > 0 {
-			m.cursorCol--
-			m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
-			m.adjustViewport()
-		}
+		m.moveCursorCols(-1)
+		m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
 	case key.Matches(msg, m.keys.Right):
-		if m.cursorCol < sheet.MaxCols-1 {
-			m.cursorCol++
-			m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
-			m.adjustViewport()
-		}
+		m.moveCursorCols(1)
+		m.selectEnd = [2]int{m.cursorRow, m.cursorCol}
 	case key.Matches(msg, m.keys.SelectRange):
 		m.mode = models.ModeNormal
 		m.status = models.StatusMsg{
@@ -0,0 +1,582 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/CodeOne45/vex-tui/internal/loader"
+	"github.com/CodeOne45/vex-tui/internal/theme"
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// excelEpoch is day zero of Excel's date serial numbers
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// dateLayouts and timeLayouts are the formats dates and times may be
+// entered in, besides serial numbers
+var (
+	dateLayouts = []string{"2006-01-02", "2006/01/02", "1/2/2006", "1/2/06", "01-02-06", "2 Jan 2006", "Jan 2, 2006", "2006-01-02 15:04", "2006-01-02 15:04:05"}
+	timeLayouts = []string{"15:04", "15:04:05", "3:04 PM", "3:04:05 PM", "3:04PM"}
+)
+
+// validationTypeNames are the words :validate and messages use for the
+// validation types
+var validationTypeNames = map[models.ValidationType]string{
+	models.ValidateList:       "list",
+	models.ValidateWhole:      "whole",
+	models.ValidateDecimal:    "decimal",
+	models.ValidateDate:       "date",
+	models.ValidateTime:       "time",
+	models.ValidateTextLength: "length",
+	models.ValidateCustom:     "custom",
+}
+
+// validationOpSymbols are the operators :validate takes before a single
+// bound
+var validationOpSymbols = map[string]models.ValidationOp{
+	"=":  models.ValidateEqual,
+	"==": models.ValidateEqual,
+	"!=": models.ValidateNotEqual,
+	"<>": models.ValidateNotEqual,
+	">":  models.ValidateGreater,
+	">=": models.ValidateGreaterEq,
+	"<":  models.ValidateLess,
+	"<=": models.ValidateLessEq,
+}
+
+// dateSerial reads a date, or an Excel serial number, as a serial number
+func dateSerial(s string) (float64, bool) {
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n, true
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Sub(excelEpoch).Hours() / 24, true
+		}
+	}
+	return 0, false
+}
+
+// timeSerial reads a time of day, or a fraction of a day, as a fraction of
+// a day
+func timeSerial(s string) (float64, bool) {
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n, true
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, strings.ToUpper(s)); err == nil {
+			return float64(t.Hour()*3600+t.Minute()*60+t.Second()) / 86400, true
+		}
+	}
+	return 0, false
+}
+
+// validationNumber reads a value as the number a rule of type typ compares:
+// the number itself, a date or time as a serial number, or the length of
+// text
+func validationNumber(typ models.ValidationType, value string) (float64, bool) {
+	switch typ {
+	case models.ValidateDate:
+		return dateSerial(value)
+	case models.ValidateTime:
+		return timeSerial(value)
+	case models.ValidateTextLength:
+		return float64(utf8.RuneCountInString(value)), true
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || typ == models.ValidateWhole && n != math.Trunc(n) {
+		return 0, false
+	}
+	return n, true
+}
+
+// validationBound reads a bound of a rule, evaluating it when it is a
+// formula
+func (m Model) validationBound(typ models.ValidationType, bound string) (float64, bool) {
+	if n, err := strconv.ParseFloat(bound, 64); err == nil {
+		return n, true
+	}
+	if bound == "" {
+		return 0, false
+	}
+	result := m.evaluateFormula(strings.TrimPrefix(bound, "="))
+	if n, err := strconv.ParseFloat(result, 64); err == nil {
+		return n, true
+	}
+	switch typ {
+	case models.ValidateDate:
+		return dateSerial(result)
+	case models.ValidateTime:
+		return timeSerial(result)
+	}
+	return 0, false
+}
+
+// formatBound shows a bound of a rule the way it would be entered: dates
+// and times as such rather than serial numbers
+func formatBound(typ models.ValidationType, bound string) string {
+	n, err := strconv.ParseFloat(bound, 64)
+	switch {
+	case err != nil:
+		return "=" + strings.TrimPrefix(bound, "=")
+	case typ == models.ValidateDate:
+		return excelEpoch.Add(time.Duration(n * 24 * float64(time.Hour))).Format("2006-01-02")
+	case typ == models.ValidateTime:
+		seconds := int(math.Round(n * 86400))
+		if seconds%60 != 0 {
+			return fmt.Sprintf("%02d:%02d:%02d", seconds/3600%24, seconds/60%60, seconds%60)
+		}
+		return fmt.Sprintf("%02d:%02d", seconds/3600%24, seconds/60%60)
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// validationAt returns the validation rule of a cell of the current sheet,
+// or nil. Where rules overlap the last one applies.
+func (m Model) validationAt(row, col int) *models.Validation {
+	rules := m.sheets[m.currentSheet].Validations
+	for i := len(rules) - 1; i >= 0; i-- {
+		for _, r := range rules[i].Ranges {
+			if row >= r.StartRow && row <= r.EndRow && col >= r.StartCol && col <= r.EndCol {
+				return &rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// listChoices returns the values a list rule offers: its own, or those of
+// the cells it takes them from
+func (m Model) listChoices(rule models.Validation) []string {
+	if rule.Source == "" {
+		return rule.Values
+	}
+	sheetName, r, ok := loader.ParseRange(rule.Source)
+	if !ok {
+		return nil
+	}
+	idx := m.currentSheet
+	if sheetName != "" {
+		if idx = m.sheetIndex(sheetName); idx < 0 {
+			return nil
+		}
+	}
+
+	sheet := m.sheets[idx]
+	var choices []string
+	for row := r.StartRow; row <= r.EndRow && row < len(sheet.Rows); row++ {
+		for col := r.StartCol; col <= r.EndCol && col < len(sheet.Rows[row]); col++ {
+			if value := sheet.Rows[row][col].Value; value != "" {
+				choices = append(choices, value)
+			}
+		}
+	}
+	return choices
+}
+
+// matchChoice returns the index of the choice equal to value, ignoring
+// case, or -1
+func matchChoice(choices []string, value string) int {
+	for i, choice := range choices {
+		if strings.EqualFold(choice, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// allows reports whether a rule lets a value into its cells. Bounds that do
+// not evaluate to a number leave the value unchecked, as do custom rules.
+func (m Model) allows(rule models.Validation, value string) bool {
+	if value == "" {
+		return rule.AllowBlank
+	}
+	switch rule.Type {
+	case models.ValidateCustom:
+		return true
+	case models.ValidateList:
+		return matchChoice(m.listChoices(rule), value) >= 0
+	}
+
+	n, ok := validationNumber(rule.Type, value)
+	if !ok {
+		return false
+	}
+	low, ok := m.validationBound(rule.Type, rule.Min)
+	if !ok {
+		return true
+	}
+	high := low
+	if rule.Op == models.ValidateBetween || rule.Op == models.ValidateNotBetween {
+		if high, ok = m.validationBound(rule.Type, rule.Max); !ok {
+			return true
+		}
+	}
+
+	switch rule.Op {
+	case models.ValidateBetween:
+		return n >= low && n <= high
+	case models.ValidateNotBetween:
+		return n < low || n > high
+	case models.ValidateEqual:
+		return n == low
+	case models.ValidateNotEqual:
+		return n != low
+	case models.ValidateGreater:
+		return n > low
+	case models.ValidateGreaterEq:
+		return n >= low
+	case models.ValidateLess:
+		return n < low
+	default:
+		return n <= low
+	}
+}
+
+// describeValidation says in words what a rule allows, such as a whole
+// number between 1 and 10
+func (m Model) describeValidation(rule models.Validation) string {
+	switch rule.Type {
+	case models.ValidateList:
+		choices := m.listChoices(rule)
+		if len(choices) > 6 {
+			choices = append(choices[:6:6], "...")
+		}
+		return "one of " + strings.Join(choices, ", ")
+	case models.ValidateCustom:
+		return "a value allowed by =" + strings.TrimPrefix(rule.Min, "=")
+	}
+
+	kind := map[models.ValidationType]string{
+		models.ValidateWhole:      "a whole number",
+		models.ValidateDecimal:    "a number",
+		models.ValidateDate:       "a date",
+		models.ValidateTime:       "a time",
+		models.ValidateTextLength: "text with a length",
+	}[rule.Type]
+	low, high := formatBound(rule.Type, rule.Min), formatBound(rule.Type, rule.Max)
+	switch rule.Op {
+	case models.ValidateBetween:
+		return fmt.Sprintf("%s between %s and %s", kind, low, high)
+	case models.ValidateNotBetween:
+		return fmt.Sprintf("%s not between %s and %s", kind, low, high)
+	case models.ValidateEqual:
+		return fmt.Sprintf("%s equal to %s", kind, low)
+	case models.ValidateNotEqual:
+		return fmt.Sprintf("%s other than %s", kind, low)
+	case models.ValidateGreater:
+		return fmt.Sprintf("%s greater than %s", kind, low)
+	case models.ValidateGreaterEq:
+		return fmt.Sprintf("%s of at least %s", kind, low)
+	case models.ValidateLess:
+		return fmt.Sprintf("%s less than %s", kind, low)
+	default:
+		return fmt.Sprintf("%s of at most %s", kind, low)
+	}
+}
+
+// validateEdit checks the value being edited against the cell's validation
+// rule and reports whether it may be committed. Rules that stop invalid
+// values keep the edit open with their message; the others let the value
+// in with a warning. Formulas are not checked, as in Excel.
+func (m *Model) validateEdit() bool {
+	rule := m.validationAt(m.cursorRow, m.cursorCol)
+	value := strings.TrimSpace(m.editInput.Value())
+	if rule == nil || strings.HasPrefix(value, "=") {
+		return true
+	}
+	if rule.Type == models.ValidateList && value != "" {
+		// Choices are matched ignoring case and entered as listed
+		choices := m.listChoices(*rule)
+		if i := matchChoice(choices, value); i >= 0 {
+			m.editInput.SetValue(choices[i])
+			return true
+		}
+	} else if m.allows(*rule, value) {
+		return true
+	}
+
+	message := rule.Error
+	if message == "" {
+		message = "Value must be " + m.describeValidation(*rule)
+	}
+	if rule.ErrorTitle != "" {
+		message = rule.ErrorTitle + ": " + message
+	}
+	message = ui.ColIndexToLetter(m.cursorCol) + strconv.Itoa(m.cursorRow+1) + ": " + message
+
+	if rule.ErrorStyle == "stop" {
+		m.status = models.StatusMsg{Message: message, Type: models.StatusError}
+		return false
+	}
+	m.status = models.StatusMsg{Message: message, Type: models.StatusWarning}
+	return true
+}
+
+// startPicker shows the prompt of the cursor cell's validation rule as its
+// edit starts, and offers the choices of a list rule
+func (m *Model) startPicker() {
+	m.pickChoices, m.pickCursor = nil, 0
+	rule := m.validationAt(m.cursorRow, m.cursorCol)
+	if rule == nil {
+		return
+	}
+	switch {
+	case rule.PromptTitle != "" && rule.Prompt != "":
+		m.status = models.StatusMsg{Message: rule.PromptTitle + ": " + rule.Prompt, Type: models.StatusInfo}
+	case rule.PromptTitle != "" || rule.Prompt != "":
+		m.status = models.StatusMsg{Message: rule.PromptTitle + rule.Prompt, Type: models.StatusInfo}
+	}
+	if rule.Type != models.ValidateList || rule.NoDropDown {
+		return
+	}
+	m.pickChoices = m.listChoices(*rule)
+	m.pickCursor = ui.Max(0, matchChoice(m.pickChoices, strings.TrimSpace(m.editInput.Value())))
+}
+
+// movePicker moves the highlighted choice by step and puts it in the edit
+func (m *Model) movePicker(step int) {
+	m.pickCursor = ui.Max(0, ui.Min(m.pickCursor+step, len(m.pickChoices)-1))
+	m.editInput.SetValue(m.pickChoices[m.pickCursor])
+	m.editInput.CursorEnd()
+}
+
+// syncPicker highlights the first choice starting with what has been typed
+func (m *Model) syncPicker() {
+	typed := strings.ToLower(strings.TrimSpace(m.editInput.Value()))
+	for i, choice := range m.pickChoices {
+		if strings.HasPrefix(strings.ToLower(choice), typed) {
+			m.pickCursor = i
+			return
+		}
+	}
+}
+
+// renderPicker renders the choices of a list rule over the cell being
+// edited
+func (m Model) renderPicker() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+	cellRef := ui.ColIndexToLetter(m.cursorCol) + strconv.Itoa(m.cursorRow+1)
+
+	content := m.styles.ModalTitle.Render("▾ Choose a value for "+cellRef) + "\n\n"
+	content += m.editInput.View() + "\n\n"
+
+	// Window of choices around the highlighted one
+	const listHeight = 10
+	start := ui.Max(0, ui.Min(m.pickCursor-listHeight/2, len(m.pickChoices)-listHeight))
+	end := ui.Min(start+listHeight, len(m.pickChoices))
+	for i := start; i < end; i++ {
+		style := lipgloss.NewStyle().Foreground(t.Text)
+		marker := "  "
+		if i == m.pickCursor {
+			style = style.Foreground(t.Accent).Bold(true)
+			marker = "▸ "
+		}
+		content += marker + style.Render(ui.Truncate(m.pickChoices[i], 52)) + "\n"
+	}
+	if len(m.pickChoices) > listHeight {
+		content += dim.Render(fmt.Sprintf("%d/%d choices", m.pickCursor+1, len(m.pickChoices))) + "\n"
+	}
+
+	content += dim.Italic(true).Render("\n↑↓ choose • type to jump • Enter set • Tab next cell • Esc cancel")
+	return m.styles.Modal.Width(60).Render(content)
+}
+
+// parseValidation reads the arguments of :validate: a type followed by the
+// choices of a list, comma separated or =range, or by the bounds of the
+// others, either min max or an operator and one bound
+func parseValidation(args string) (models.Validation, error) {
+	name, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	rest = strings.TrimSpace(rest)
+	rule := models.Validation{AllowBlank: true, ErrorStyle: "stop"}
+	found := false
+	for typ, typeName := range validationTypeNames {
+		if strings.EqualFold(name, typeName) {
+			rule.Type, found = typ, true
+		}
+	}
+	if !found || rule.Type == models.ValidateCustom {
+		return rule, fmt.Errorf("unknown type %q", name)
+	}
+	if rest == "" && rule.Type == models.ValidateList {
+		return rule, fmt.Errorf("missing choices")
+	}
+	if rest == "" {
+		return rule, fmt.Errorf("missing bounds")
+	}
+
+	if rule.Type == models.ValidateList {
+		if source, ok := strings.CutPrefix(rest, "="); ok {
+			if _, _, ok := loader.ParseRange(source); !ok {
+				return rule, fmt.Errorf("bad range %q", source)
+			}
+			rule.Source = source
+			return rule, nil
+		}
+		for _, choice := range strings.Split(rest, ",") {
+			if choice = strings.TrimSpace(choice); choice != "" {
+				rule.Values = append(rule.Values, choice)
+			}
+		}
+		return rule, nil
+	}
+
+	fields := strings.Fields(rest)
+	op, isOp := validationOpSymbols[fields[0]]
+	var bounds []string
+	switch {
+	case len(fields) == 2 && isOp:
+		rule.Op, bounds = op, fields[1:]
+	case len(fields) == 3 && strings.EqualFold(fields[0], "not"):
+		rule.Op, bounds = models.ValidateNotBetween, fields[1:]
+	case len(fields) == 2:
+		rule.Op, bounds = models.ValidateBetween, fields
+	default:
+		return rule, fmt.Errorf("expected min max or an operator and a bound")
+	}
+
+	for i, bound := range bounds {
+		value := strings.TrimPrefix(bound, "=")
+		if !strings.HasPrefix(bound, "=") {
+			// Bounds are stored as xlsx writes them: dates and times as
+			// serial numbers
+			n, ok := validationNumber(rule.Type, bound)
+			if rule.Type == models.ValidateTextLength {
+				n, ok = validationNumber(models.ValidateWhole, bound)
+			}
+			if !ok {
+				return rule, fmt.Errorf("bad %s %q", validationTypeNames[rule.Type], bound)
+			}
+			value = strconv.FormatFloat(n, 'f', -1, 64)
+		}
+		if i == 0 {
+			rule.Min = value
+		} else {
+			rule.Max = value
+		}
+	}
+	return rule, nil
+}
+
+// setValidation puts a rule on the selected cells, or the cursor's,
+// replacing the rules they had; a nil rule just removes them
+func (m *Model) setValidation(rule *models.Validation) {
+	if !m.canEdit() {
+		return
+	}
+	sheet := &m.sheets[m.currentSheet]
+	startRow, startCol, endRow, endCol := m.selectedRange()
+	cut := models.CellRange{StartRow: startRow, StartCol: startCol, EndRow: endRow, EndCol: endCol}
+	label := ui.ColIndexToLetter(startCol) + strconv.Itoa(startRow+1)
+	if startRow != endRow || startCol != endCol {
+		label += ":" + ui.ColIndexToLetter(endCol) + strconv.Itoa(endRow+1)
+	}
+	stepLabel := "validate " + label
+	if rule == nil {
+		stepLabel = "remove validation from " + label
+	}
+	step := m.newStep(stepLabel, stepLayout)
+
+	removed := false
+	var rules []models.Validation
+	for _, existing := range sheet.Validations {
+		var ranges []models.CellRange
+		for _, r := range existing.Ranges {
+			left := subtractRange(r, cut)
+			removed = removed || len(left) != 1 || left[0] != r
+			ranges = append(ranges, left...)
+		}
+		if len(ranges) > 0 {
+			existing.Ranges = ranges
+			rules = append(rules, existing)
+		}
+	}
+	sheet.Validations = rules
+	m.isSelecting = false
+
+	if rule != nil {
+		rule.Ranges = []models.CellRange{cut}
+		sheet.Validations = append(sheet.Validations, *rule)
+	}
+	m.recordStep(step)
+
+	switch {
+	case rule != nil:
+		m.status = models.StatusMsg{Message: fmt.Sprintf("Validation on %s: %s", label, m.describeValidation(*rule)), Type: models.StatusSuccess}
+	case removed:
+		m.status = models.StatusMsg{Message: "Removed validation from " + label, Type: models.StatusSuccess}
+	default:
+		m.status = models.StatusMsg{Message: "No validation on " + label, Type: models.StatusInfo}
+	}
+}
+
+// sameValidation reports whether two rules are the same
+func sameValidation(a, b models.Validation) bool {
+	return slices.Equal(a.Ranges, b.Ranges) && a.Type == b.Type && a.Op == b.Op &&
+		a.Min == b.Min && a.Max == b.Max && slices.Equal(a.Values, b.Values) && a.Source == b.Source &&
+		a.AllowBlank == b.AllowBlank && a.NoDropDown == b.NoDropDown &&
+		a.ErrorStyle == b.ErrorStyle && a.ErrorTitle == b.ErrorTitle && a.Error == b.Error &&
+		a.PromptTitle == b.PromptTitle && a.Prompt == b.Prompt
+}
+
+// subtractRange returns what is left of r once the cells of cut are taken
+// out, as up to four ranges above, below, left and right of the cut
+func subtractRange(r, cut models.CellRange) []models.CellRange {
+	if cut.StartRow > r.EndRow || cut.EndRow < r.StartRow || cut.StartCol > r.EndCol || cut.EndCol < r.StartCol {
+		return []models.CellRange{r}
+	}
+	var left []models.CellRange
+	if cut.StartRow > r.StartRow {
+		left = append(left, models.CellRange{StartRow: r.StartRow, StartCol: r.StartCol, EndRow: cut.StartRow - 1, EndCol: r.EndCol})
+	}
+	if cut.EndRow < r.EndRow {
+		left = append(left, models.CellRange{StartRow: cut.EndRow + 1, StartCol: r.StartCol, EndRow: r.EndRow, EndCol: r.EndCol})
+	}
+	top, bottom := ui.Max(r.StartRow, cut.StartRow), ui.Min(r.EndRow, cut.EndRow)
+	if cut.StartCol > r.StartCol {
+		left = append(left, models.CellRange{StartRow: top, StartCol: r.StartCol, EndRow: bottom, EndCol: cut.StartCol - 1})
+	}
+	if cut.EndCol < r.EndCol {
+		left = append(left, models.CellRange{StartRow: top, StartCol: cut.EndCol + 1, EndRow: bottom, EndCol: r.EndCol})
+	}
+	return left
+}
+
+// shiftValidations moves the ranges of validation rules after a row, or a
+// column when cols is set, is inserted (delta 1) or deleted (delta -1) at
+// index at. Ranges across the index grow or shrink, and rules left without
+// cells are dropped.
+func shiftValidations(rules []models.Validation, at, delta int, cols bool) []models.Validation {
+	var shifted []models.Validation
+	for _, rule := range rules {
+		var ranges []models.CellRange
+		for _, r := range rule.Ranges {
+			start, end := &r.StartRow, &r.EndRow
+			if cols {
+				start, end = &r.StartCol, &r.EndCol
+			}
+			if *start > at || delta > 0 && *start == at {
+				*start += delta
+			}
+			if *end >= at {
+				*end += delta
+			}
+			if *end >= *start {
+				ranges = append(ranges, r)
+			}
+		}
+		if len(ranges) > 0 {
+			rule.Ranges = ranges
+			shifted = append(shifted, rule)
+		}
+	}
+	return shifted
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/CodeOne45/vex-tui/internal/loader"
 	"github.com/CodeOne45/vex-tui/internal/theme"
 	"github.com/CodeOne45/vex-tui/internal/ui"
 	"github.com/CodeOne45/vex-tui/pkg/models"
@@ -38,6 +39,12 @@ func (m Model) View() string {
 		return m.renderEditMode()
 	case models.ModeSaveAs:
 		return ui.RenderModal(m.width, m.height, m.renderSaveAs())
+	case models.ModeBuffers:
+		return ui.RenderModal(m.width, m.height, m.renderBufferList())
+	case models.ModeFilter:
+		return ui.RenderModal(m.width, m.height, m.renderFilter())
+	case models.ModeReplace:
+		return ui.RenderModal(m.width, m.height, m.renderReplace())
 	default:
 		return m.renderNormal()
 	}
@@ -63,28 +70,45 @@ func (m Model) renderNormal() string {
 	var b strings.Builder
 
 	// Title bar
-	title := fmt.Sprintf("📊 %s", m.filename)
+	title := fmt.Sprintf("📊 %s", m.displayName())
 	if len(m.sheets) > 1 {
 		title += fmt.Sprintf(" • %s (%d/%d)", sheet.Name, m.currentSheet+1, len(m.sheets))
 	} else {
 		title += fmt.Sprintf(" • %s", sheet.Name)
 	}
+	if len(m.buffers) > 1 {
+		title += fmt.Sprintf(" [file %d/%d]", m.currentBuffer+1, len(m.buffers))
+	}
 	b.WriteString(m.styles.Title.Render(title))
 	b.WriteString("\n")
 
-	// Formula bar
+	// Formula bar, then the sheet tabs when there is more than one sheet
 	b.WriteString(m.renderFormulaBar())
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	b.WriteString(m.renderSheetTabs())
+	b.WriteString("\n")
 
-	// Render table
-	b.WriteString(m.renderTable())
+	// Render table, split into panes when there are several
+	b.WriteString(m.renderPanes())
 
 	// Status bar
 	b.WriteString("\n")
 	b.WriteString(m.renderStatusBar())
 
-	// Search bar (vim-style at bottom)
-	if m.mode == models.ModeSearch || m.searchQuery != "" {
+	// Active filters
+	if len(sheet.Filters) > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.renderFilterBar())
+	}
+
+	// Command line or search bar (vim-style at bottom)
+	if m.mode == models.ModeCommand {
+		b.WriteString("\n")
+		b.WriteString(m.renderCommandBar())
+	} else if m.mode == models.ModeReplaceConfirm {
+		b.WriteString("\n")
+		b.WriteString(m.styles.SearchBar.Render(m.styles.SearchPrompt.Render("⇄ ") + m.replacePrompt()))
+	} else if m.mode == models.ModeSearch || m.searchQuery != "" {
 		b.WriteString("\n")
 		b.WriteString(m.renderSearchBar())
 	}
@@ -127,23 +151,55 @@ func (m Model) renderFormulaBar() string {
 // renderTable renders the spreadsheet table
 func (m Model) renderTable() string {
 	sheet := m.sheets[m.currentSheet]
-	visibleRows := ui.Max(1, m.height-9)
-	visibleCols := ui.Max(1, (m.width-8)/(ui.MinCellWidth+2))
 
 	var b strings.Builder
 	sep := m.styles.Separator.Render("│")
 
-	// Column headers
-	b.WriteString(m.styles.RowNum.Render(""))
-	b.WriteString(sep)
-
-	for col := m.offsetCol; col < ui.Min(m.offsetCol+visibleCols, sheet.MaxCols); col++ {
-		width := ui.MinCellWidth
-		if w, ok := sheet.ColWidths[col]; ok && w > 0 {
-			width = w
+	// Frozen columns end with a heavier separator and frozen rows with an
+	// underline
+	cols := m.gridCols()
+	lastFrozenCol := m.frozenCols() - 1
+	colSep := func(col int) string {
+		if col == lastFrozenCol {
+			return m.styles.Separator.Render("┃")
 		}
+		return sep
+	}
+	lastFrozenRow := -1
+	if frozen := m.frozenRows(); frozen > 0 {
+		lastFrozenRow = m.rowAt(frozen - 1)
+	}
+
+	// Column headers, after the pane number when the view is split
+	outline := lipgloss.NewStyle().Foreground(theme.GetCurrentTheme().DimText)
+	b.WriteString(strings.Repeat(" ", m.outlineWidth()))
+	switch {
+	case len(m.panes) < 2:
+		b.WriteString(m.styles.RowNum.Render(""))
+	case m.unfocused:
+		b.WriteString(m.styles.RowNum.Render(fmt.Sprintf("%d", m.activePane+1)))
+	default:
+		b.WriteString(m.styles.SelectedRowNum.Render(fmt.Sprintf("%d", m.activePane+1)))
+	}
+
+	// A marker in the header separator shows where columns are hidden
+	hiddenMark := lipgloss.NewStyle().Foreground(theme.GetCurrentTheme().Warning).Render("‖")
+	if len(cols) > 0 && cols[0] > 0 && sheet.HiddenCols[cols[0]-1] {
+		b.WriteString(hiddenMark)
+	} else {
+		b.WriteString(sep)
+	}
+
+	for _, col := range cols {
+		width := colWidth(sheet, col)
 
 		colLetter := ui.ColIndexToLetter(col)
+		if indicator := m.sortIndicator(col); indicator != "" {
+			colLetter += " " + indicator
+		}
+		if m.isFiltered(col) {
+			colLetter += " ▾"
+		}
 		headerStyle := m.styles.Header
 		if col == m.cursorCol {
 			headerStyle = m.styles.HeaderHighlight
@@ -152,44 +208,63 @@ func (m Model) renderTable() string {
 		// Adjust style width
 		headerStyle = headerStyle.Width(width)
 		b.WriteString(headerStyle.Render(ui.PadCenter(colLetter, width)))
-		b.WriteString(sep)
-	}
-	b.WriteString("\n")
-
-	// Data rows
-	endRow := ui.Min(m.offsetRow+visibleRows, sheet.MaxRows)
-	for row := m.offsetRow; row < endRow; row++ {
-		// Row number
-		if row == m.cursorRow {
-			b.WriteString(m.styles.SelectedRowNum.Render(fmt.Sprintf("%d", row+1)))
+		if sheet.HiddenCols[col+1] {
+			b.WriteString(hiddenMark)
 		} else {
-			b.WriteString(m.styles.RowNum.Render(fmt.Sprintf("%d", row+1)))
+			b.WriteString(colSep(col))
 		}
-		b.WriteString(sep)
+	}
+	b.WriteString("\n")
 
-		// Cells
-		if row < len(sheet.Rows) {
-			for col := m.offsetCol; col < ui.Min(m.offsetCol+visibleCols, sheet.MaxCols); col++ {
-				cellText := ""
-				width := ui.MinCellWidth
-				if w, ok := sheet.ColWidths[col]; ok && w > 0 {
-					width = w
-				}
+	// URLs become terminal links on lines with room left for their escape
+	// sequences. Split panes are clipped by width, which would cut them.
+	lineWidth := m.gutterWidth() + 1
+	for _, col := range cols {
+		lineWidth += colWidth(sheet, col) + 1
+	}
+	linkRoom := m.width - lineWidth
+	if len(m.panes) > 1 {
+		linkRoom = 0
+	}
 
-				if col < len(sheet.Rows[row]) {
-					cell := sheet.Rows[row][col]
-					if m.showFormulas && cell.Formula != "" {
-						cellText = "=" + cell.Formula
-					} else {
-						cellText = cell.Value
-					}
-				}
+	// Data rows, frozen rows first, skipping rows hidden by filters. Rows
+	// taller than one line show their cells word-wrapped.
+	for _, r := range m.gridRows() {
+		row := r.row
+		texts := make([][]string, len(cols))
+		for i, col := range cols {
+			text := ""
+			if row < len(sheet.Rows) && col < len(sheet.Rows[row]) {
+				text = m.displayText(sheet.Rows[row][col])
+			}
+			texts[i] = cellLines(text, colWidth(sheet, col), r.lines)
+		}
 
-				cellText = ui.TruncateToWidth(cellText, width)
+		for line := 0; line < r.lines; line++ {
+			// Outline gutter, then the row number on the first line
+			if m.outlineWidth() > 0 {
+				b.WriteString(outline.Render(m.outlineMarks(row, line == 0)))
+			}
+			number := ""
+			if line == 0 {
+				number = fmt.Sprintf("%d", row+1)
+			}
+			if row == m.cursorRow {
+				b.WriteString(m.styles.SelectedRowNum.Render(number))
+			} else {
+				b.WriteString(m.styles.RowNum.Render(number))
+			}
+			b.WriteString(sep)
 
+			// Cells
+			room := linkRoom
+			for i, col := range cols {
 				// Determine style
 				var style lipgloss.Style
-				if row == m.cursorRow && col == m.cursorCol {
+				if row == m.cursorRow && col == m.cursorCol && m.unfocused {
+					// Panes without focus mark their cursor more quietly
+					style = m.styles.RowHighlight
+				} else if row == m.cursorRow && col == m.cursorCol {
 					style = m.styles.SelectedCell
 				} else if m.isSelecting && m.isInSelection(row, col) {
 					// Highlight selection with different color
@@ -206,54 +281,113 @@ func (m Model) renderTable() string {
 				} else {
 					style = m.styles.Cell
 				}
-				
-				style = style.Width(width)
-				b.WriteString(style.Render(cellText))
-				b.WriteString(sep)
-			}
-		} else {
-			// Empty row
-			for col := m.offsetCol; col < ui.Min(m.offsetCol+visibleCols, sheet.MaxCols); col++ {
-				width := ui.MinCellWidth
-				if w, ok := sheet.ColWidths[col]; ok && w > 0 {
-					width = w
+
+				var cell models.Cell
+				if row < len(sheet.Rows) && col < len(sheet.Rows[row]) {
+					cell = sheet.Rows[row][col]
 				}
 
-				var style lipgloss.Style
-				if row == m.cursorRow && col == m.cursorCol {
-					style = m.styles.SelectedCell
-				} else if row == m.cursorRow {
-					style = m.styles.RowHighlight
-				} else if col == m.cursorCol {
-					style = m.styles.ColHighlight
+				// Links are underlined like in Excel
+				style = style.Width(colWidth(sheet, col)).Underline(cell.Link != "" || row == lastFrozenRow && line == r.lines-1)
+				var rendered string
+				if line == 0 && cell.Comment != "" {
+					// Commented cells are marked in their top right corner
+					width := colWidth(sheet, col)
+					rendered = style.Width(width-1).Render(ui.TruncateToWidth(strings.TrimRight(texts[i][line], " "), width-1)) +
+						style.Width(1).Foreground(theme.GetCurrentTheme().Warning).Render(commentMark)
 				} else {
-					style = m.styles.Cell
+					rendered = style.Render(texts[i][line])
+				}
+				if terminalLink(cell.Link) {
+					if cost := hyperlinkCost(cell.Link); cost <= room {
+						rendered = hyperlink(cell.Link, rendered)
+						room -= cost
+					}
 				}
-				style = style.Width(width)
-				b.WriteString(style.Render(strings.Repeat(" ", width)))
-				b.WriteString(sep)
+				b.WriteString(rendered)
+				b.WriteString(colSep(col))
 			}
+			b.WriteString("\n")
 		}
-		b.WriteString("\n")
 	}
 
 	return b.String()
 }
 
+// commentMark is drawn in the top right corner of commented cells
+const commentMark = "▝"
+
+// cellLines lays out a cell's text over the lines of its row, each padded to
+// the column width. A one-line row shows the text flattened; taller rows
+// wrap it and end with ... when it does not fit.
+func cellLines(text string, width, lines int) []string {
+	if lines <= 1 {
+		return []string{ui.TruncateToWidth(text, width)}
+	}
+
+	wrapped := ui.WrapLines(text, width)
+	if len(wrapped) > lines {
+		last := strings.Join(wrapped[lines-1:], " ")
+		wrapped = append(wrapped[:lines-1], ui.TruncateToWidth(last+" ...", width))
+	}
+	out := make([]string, lines)
+	for i := range out {
+		if i < len(wrapped) {
+			out[i] = ui.TruncateToWidth(wrapped[i], width)
+		} else {
+			out[i] = strings.Repeat(" ", width)
+		}
+	}
+	return out
+}
+
+// renderSheetTabs renders the tab line of a workbook with several sheets,
+// highlighting the current sheet
+func (m Model) renderSheetTabs() string {
+	if len(m.sheets) < 2 {
+		return ""
+	}
+	var b strings.Builder
+	for i, tab := range m.sheetTabs() {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		style := m.styles.Header
+		if tab.sheet == m.currentSheet {
+			style = m.styles.HeaderHighlight
+		}
+		b.WriteString(style.Width(0).Render(tab.label))
+	}
+	return b.String()
+}
+
 // renderStatusBar renders the status bar at the bottom
 func (m Model) renderStatusBar() string {
 	sheet := m.sheets[m.currentSheet]
 	t := theme.GetCurrentTheme()
 
+	rows := fmt.Sprintf(" %d", sheet.MaxRows)
+	if m.rowView != nil {
+		rows = fmt.Sprintf(" %s of %s", formatCount(m.shownRows()), formatCount(sheet.MaxRows))
+	}
+
 	parts := []string{
 		lipgloss.NewStyle().Foreground(t.Secondary).Bold(true).Render("Rows:") +
-			lipgloss.NewStyle().Foreground(t.Text).Render(fmt.Sprintf(" %d", sheet.MaxRows)),
+			lipgloss.NewStyle().Foreground(t.Text).Render(rows),
 		lipgloss.NewStyle().Foreground(t.Secondary).Bold(true).Render("Cols:") +
 			lipgloss.NewStyle().Foreground(t.Text).Render(fmt.Sprintf(" %d", sheet.MaxCols)),
 		lipgloss.NewStyle().Foreground(t.Secondary).Bold(true).Render("Pos:") +
 			lipgloss.NewStyle().Foreground(t.Text).Render(fmt.Sprintf(" %s", ui.ColIndexToLetter(m.cursorCol)+fmt.Sprintf("%d", m.cursorRow+1))),
 	}
 
+	if sheet.Source != nil && !sheet.Source.Complete() {
+		parts[0] += lipgloss.NewStyle().Foreground(t.DimText).Render(fmt.Sprintf(" (%d loaded)", len(sheet.Rows)))
+	}
+
+	if sheet.ReadOnly {
+		parts = append(parts, lipgloss.NewStyle().Foreground(t.Warning).Render("Read-only"))
+	}
+
 	if m.showFormulas {
 		parts = append(parts, lipgloss.NewStyle().Foreground(t.Accent).Render("Formulas"))
 	}
@@ -279,13 +413,28 @@ func (m Model) renderStatusBar() string {
 func (m Model) renderSearchBar() string {
 	t := theme.GetCurrentTheme()
 
+	tags, on := m.searchOptionTags()
+
 	if m.mode == models.ModeSearch {
 		prompt := m.styles.SearchPrompt.Render("/")
 		input := m.searchInput.View()
-		return m.styles.SearchBar.Render(prompt + input)
+
+		// Every option, lit when on, with its toggle key
+		keys := []string{"r", "c", "w", "f", "s"}
+		options := make([]string, len(tags))
+		for i, tag := range tags {
+			options[i] = m.optionTag(tag, keys[i], on[i])
+		}
+		hint := lipgloss.NewStyle().Foreground(t.DimText).Render("  alt+ ")
+		return m.styles.SearchBar.Render(prompt + input + hint + strings.Join(options, " "))
 	} else if m.searchQuery != "" {
 		searchInfo := m.styles.SearchPrompt.Render("/") +
 			lipgloss.NewStyle().Foreground(t.Text).Render(m.searchQuery)
+		for i, tag := range tags {
+			if on[i] {
+				searchInfo += lipgloss.NewStyle().Foreground(t.Accent).Render(" [" + tag + "]")
+			}
+		}
 		if len(m.searchResults) > 0 {
 			searchInfo += lipgloss.NewStyle().
 				Foreground(t.DimText).
@@ -296,6 +445,27 @@ func (m Model) renderSearchBar() string {
 	return ""
 }
 
+// renderFilterBar lists the active filters of the current sheet
+func (m Model) renderFilterBar() string {
+	t := theme.GetCurrentTheme()
+	filters := m.sheets[m.currentSheet].Filters
+	parts := make([]string, len(filters))
+	for i, f := range filters {
+		parts[i] = filterString(f)
+	}
+	info := m.styles.SearchPrompt.Render("⏷ Filter: ") +
+		lipgloss.NewStyle().Foreground(t.Text).Render(strings.Join(parts, " • "))
+	return m.styles.SearchBar.Render(info)
+}
+
+// renderCommandBar renders the : command prompt with the tables :sql can use
+func (m Model) renderCommandBar() string {
+	t := theme.GetCurrentTheme()
+	prompt := m.styles.SearchPrompt.Render(":")
+	hint := lipgloss.NewStyle().Foreground(t.DimText).Render("  " + m.queryTablesHint())
+	return m.styles.SearchBar.Render(prompt + m.commandInput.View() + hint)
+}
+
 // renderDetail renders the cell detail modal
 func (m Model) renderDetail() string {
 	sheet := m.sheets[m.currentSheet]
@@ -316,10 +486,32 @@ func (m Model) renderDetail() string {
 	}
 
 	content += m.styles.ModalKey.Render("Type: ") + m.styles.ModalValue.Render(ui.GetCellType(cell)) + "\n"
+
+	if cell.Comment != "" {
+		author := ""
+		if cell.CommentAuthor != "" {
+			author = " (" + cell.CommentAuthor + ")"
+		}
+		content += "\n" + m.styles.ModalKey.Render("Comment"+author+":\n") + m.styles.ModalValue.Render(strings.Join(ui.WrapLines(cell.Comment, 56), "\n")) + "\n"
+	}
+
+	if rule := m.validationAt(m.cursorRow, m.cursorCol); rule != nil {
+		content += "\n" + m.styles.ModalKey.Render("Validation:\n") + m.styles.ModalValue.Render(ui.WrapText("Must be "+m.describeValidation(*rule), 56)) + "\n"
+	}
+
+	hint := "\nPress Enter or Esc to close"
+	if cell.Link != "" {
+		target, action := cell.Link, "open"
+		if location, ok := strings.CutPrefix(cell.Link, "#"); ok {
+			target, action = location, "go to"
+		}
+		content += "\n" + m.styles.ModalKey.Render("Link:\n") + m.styles.ModalValue.Render(ui.WrapText(target, 56)) + "\n"
+		hint = "\nPress o to " + action + " the link, Enter or Esc to close"
+	}
 	content += lipgloss.NewStyle().
 		Foreground(t.DimText).
 		Italic(true).
-		Render("\nPress Enter or Esc to close")
+		Render(hint)
 
 	return m.styles.Modal.Render(content)
 }
@@ -346,13 +538,36 @@ func (m Model) renderExport() string {
 	content := m.styles.ModalTitle.Render("💾 Export Sheet") + "\n\n"
 	content += m.styles.ModalKey.Render("Filename:") + "\n"
 	content += m.exportInput.View() + "\n\n"
+	content += m.styles.ModalKey.Render("Scope:") + "\n"
+	for i, name := range exportScopeNames {
+		switch {
+		case i == m.exportScope:
+			content += lipgloss.NewStyle().Foreground(t.Accent).Bold(true).Render("→ "+name) + "\n"
+		case i == exportScopeSelection && !m.isSelecting:
+			content += lipgloss.NewStyle().Foreground(t.DimText).Render("  "+name+" (none)") + "\n"
+		default:
+			content += lipgloss.NewStyle().Foreground(t.Text).Render("  "+name) + "\n"
+		}
+	}
+	content += "\n" + lipgloss.NewStyle().
+		Foreground(t.DimText).
+		Render(m.exportFormatHint()) + "\n"
 	content += lipgloss.NewStyle().
 		Foreground(t.DimText).
-		Render("Supported formats: .csv, .json")
+		Italic(true).
+		Render("Tab to change scope, Enter to export")
 
 	return m.styles.Modal.Width(50).Render(content)
 }
 
+// exportFormatHint describes the accepted export targets for the current scope
+func (m Model) exportFormatHint() string {
+	if m.exportScope == exportScopeWorkbook {
+		return "Directory (one .csv per sheet) or a single .json file"
+	}
+	return "Supported formats: " + strings.Join(loader.ExportFormats(), " ")
+}
+
 // renderThemeSelector renders the theme selection modal
 func (m Model) renderThemeSelector() string {
 	t := theme.GetCurrentTheme()
@@ -399,6 +614,139 @@ func (m Model) renderThemeSelector() string {
 	return m.styles.Modal.Width(60).Render(content)
 }
 
+// renderBufferList renders the open files modal
+func (m Model) renderBufferList() string {
+	t := theme.GetCurrentTheme()
+
+	content := m.styles.ModalTitle.Render("📂 Open Files") + "\n\n"
+
+	for i := range m.buffers {
+		sheets := m.buffers[i].sheets
+		if i == m.currentBuffer {
+			sheets = m.sheets
+		}
+
+		numStyle := lipgloss.NewStyle().Foreground(t.Primary).Bold(true)
+		nameStyle := lipgloss.NewStyle().Foreground(t.Text)
+		if i == m.bufferCursor {
+			nameStyle = nameStyle.Foreground(t.Accent).Bold(true)
+		}
+
+		marker := "  "
+		if i == m.bufferCursor {
+			marker = "▸ "
+		}
+		line := marker + numStyle.Render(strconv.Itoa(i+1)) + "  " + nameStyle.Render(m.bufferName(i))
+		if m.bufferModified(i) {
+			line += lipgloss.NewStyle().Foreground(t.Warning).Render(" ●")
+		}
+		if i == m.currentBuffer {
+			line += lipgloss.NewStyle().Foreground(t.Accent).Render(" ✓")
+		}
+		line += lipgloss.NewStyle().Foreground(t.DimText).Render(fmt.Sprintf("  %d sheet(s)", len(sheets)))
+		content += line + "\n"
+	}
+
+	content += lipgloss.NewStyle().
+		Foreground(t.DimText).
+		Italic(true).
+		Render("\n↑↓ move • Enter/1-9 switch • ● unsaved • Esc close")
+
+	return m.styles.Modal.Width(60).Render(content)
+}
+
+// renderFilter renders the column filter dialog
+func (m Model) renderFilter() string {
+	t := theme.GetCurrentTheme()
+	dim := lipgloss.NewStyle().Foreground(t.DimText)
+
+	content := m.styles.ModalTitle.Render("⏷ Filter Column "+ui.ColIndexToLetter(m.cursorCol)) + "\n\n"
+	content += lipgloss.NewStyle().Foreground(t.Secondary).Bold(true).Render("Condition: ") +
+		lipgloss.NewStyle().Foreground(t.Accent).Render(filterOpNames[m.filterOp]) + "\n\n"
+
+	if m.filterOp != models.FilterValues {
+		content += m.filterInput.View() + "\n"
+		content += dim.Italic(true).Render("\nTab condition • Enter apply • Ctrl+X clear • Esc cancel")
+		return m.styles.Modal.Width(60).Render(content)
+	}
+
+	if len(m.filterValues) == 0 {
+		content += dim.Render("No values in this column") + "\n"
+	}
+
+	// Window of values around the cursor
+	const listHeight = 10
+	start := ui.Max(0, ui.Min(m.filterCursor-listHeight/2, len(m.filterValues)-listHeight))
+	end := ui.Min(start+listHeight, len(m.filterValues))
+	for i := start; i < end; i++ {
+		value := m.filterValues[i]
+		box := "[ ] "
+		if m.filterChecked[strings.ToLower(value)] {
+			box = "[x] "
+		}
+		if value == "" {
+			value = "(blank)"
+		}
+
+		style := lipgloss.NewStyle().Foreground(t.Text)
+		marker := "  "
+		if i == m.filterCursor {
+			style = style.Foreground(t.Accent).Bold(true)
+			marker = "▸ "
+		}
+		content += marker + style.Render(box+ui.Truncate(value, 48)) + "\n"
+	}
+	if len(m.filterValues) > listHeight {
+		content += dim.Render(fmt.Sprintf("%d/%d values", m.filterCursor+1, len(m.filterValues))) + "\n"
+	}
+	if m.filterMore {
+		content += dim.Render(fmt.Sprintf("Only the first %d values are listed; the rest stay shown", maxFilterValues)) + "\n"
+	}
+
+	content += dim.Italic(true).Render("\nTab condition • Space toggle • a all • Enter apply • Ctrl+X clear • Esc cancel")
+	return m.styles.Modal.Width(60).Render(content)
+}
+
+// renderReplace renders the find/replace dialog
+func (m Model) renderReplace() string {
+	t := theme.GetCurrentTheme()
+	label := lipgloss.NewStyle().Foreground(t.Secondary).Bold(true)
+
+	content := m.styles.ModalTitle.Render("⇄ Find & Replace") + "\n\n"
+	content += label.Render("Find:    ") + m.replaceFind.View() + "\n"
+	content += label.Render("Replace: ") + m.replaceWith.View() + "\n\n"
+
+	// Options shared with search, plus rewriting inside formulas
+	tags, on := m.searchOptionTags()
+	keys := []string{"r", "c", "w", "", "s"}
+	var options []string
+	for i, tag := range tags {
+		if keys[i] == "" {
+			continue
+		}
+		options = append(options, m.optionTag(tag, keys[i], on[i]))
+	}
+	options = append(options, m.optionTag("formulas", "f", m.replaceFormulas))
+	content += strings.Join(options, " ") + "\n"
+
+	content += lipgloss.NewStyle().
+		Foreground(t.DimText).
+		Italic(true).
+		Render("\nTab switch field • Alt+key toggle • Enter confirm each • Alt+A replace all • Esc cancel")
+
+	return m.styles.Modal.Width(60).Render(content)
+}
+
+// optionTag renders a toggle label, lit when on, with its Alt key
+func (m Model) optionTag(tag, key string, on bool) string {
+	t := theme.GetCurrentTheme()
+	style := lipgloss.NewStyle().Foreground(t.DimText)
+	if on {
+		style = lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+	}
+	return style.Render(tag) + lipgloss.NewStyle().Foreground(t.DimText).Render("("+key+")")
+}
+
 // renderChart renders the chart visualization modal
 func (m Model) renderChart() string {
 	t := theme.GetCurrentTheme()
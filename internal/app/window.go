@@ -0,0 +1,295 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/ui"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pane holds the view state of one window of a split view while another
+// pane has focus. The focused pane lives directly in the Model fields, and
+// every pane shows the same sheets, so edits appear in all of them at once.
+type pane struct {
+	currentSheet int
+	cursorRow    int
+	cursorCol    int
+	offsetRow    int
+	offsetCol    int
+	selectStart  [2]int
+	selectEnd    [2]int
+	isSelecting  bool
+}
+
+// paneRect is the screen area of a pane's grid: its column header line at
+// y followed by lines of rows, width columns wide from x
+type paneRect struct {
+	x, y, width, lines int
+}
+
+// storePane copies the focused pane's state out of the model
+func (m *Model) storePane() {
+	if len(m.panes) == 0 {
+		return
+	}
+	m.panes[m.activePane] = pane{
+		currentSheet: m.currentSheet,
+		cursorRow:    m.cursorRow,
+		cursorCol:    m.cursorCol,
+		offsetRow:    m.offsetRow,
+		offsetCol:    m.offsetCol,
+		selectStart:  m.selectStart,
+		selectEnd:    m.selectEnd,
+		isSelecting:  m.isSelecting,
+	}
+}
+
+// loadPane makes pane idx the focused one, keeping it inside sheets that
+// may have shrunk since it last had focus
+func (m *Model) loadPane(idx int) {
+	p := m.panes[idx]
+	m.activePane = idx
+	m.currentSheet = ui.Min(p.currentSheet, len(m.sheets)-1)
+	sheet := m.sheets[m.currentSheet]
+	m.cursorRow = ui.Min(p.cursorRow, ui.Max(0, sheet.MaxRows-1))
+	m.cursorCol = ui.Min(p.cursorCol, ui.Max(0, sheet.MaxCols-1))
+	m.offsetRow = p.offsetRow
+	m.offsetCol = p.offsetCol
+	m.selectStart = p.selectStart
+	m.selectEnd = p.selectEnd
+	m.isSelecting = p.isSelecting
+	m.applyFilters()
+	m.adjustViewport()
+}
+
+// splitWindow opens a new pane beside the focused one, showing the given
+// sheet, or the focused pane's view when sheet is -1. Panes are stacked, or
+// side by side when vertical; splitting the other way rearranges them all.
+func (m *Model) splitWindow(vertical bool, sheet int) {
+	if len(m.panes) == 0 {
+		m.panes = []pane{{}}
+		m.activePane = 0
+	}
+	if !m.paneFits(len(m.panes)+1, vertical) {
+		m.status = models.StatusMsg{Message: "Not enough room for another pane", Type: models.StatusWarning}
+		return
+	}
+	m.splitVertical = vertical
+	m.storePane()
+
+	p := m.panes[m.activePane]
+	if sheet >= 0 && sheet != p.currentSheet {
+		p = pane{currentSheet: sheet}
+	}
+	at := m.activePane + 1
+	m.panes = append(m.panes[:at], append([]pane{p}, m.panes[at:]...)...)
+	m.loadPane(at)
+
+	m.status = models.StatusMsg{
+		Message: fmt.Sprintf("Pane %d/%d: %s", at+1, len(m.panes), m.sheets[m.currentSheet].Name),
+		Type:    models.StatusInfo,
+	}
+}
+
+// focusPane moves focus to pane idx
+func (m *Model) focusPane(idx int) {
+	if len(m.panes) < 2 || idx < 0 || idx >= len(m.panes) || idx == m.activePane {
+		return
+	}
+	m.storePane()
+	m.loadPane(idx)
+}
+
+// cyclePane moves focus to the next (step 1) or previous (step -1) pane
+func (m *Model) cyclePane(step int) {
+	if len(m.panes) < 2 {
+		m.status = models.StatusMsg{Message: "Only one pane", Type: models.StatusInfo}
+		return
+	}
+	m.focusPane((m.activePane + step + len(m.panes)) % len(m.panes))
+}
+
+// closePane closes the focused pane and focuses its neighbour
+func (m *Model) closePane() {
+	if len(m.panes) < 2 {
+		m.status = models.StatusMsg{Message: "Cannot close the last pane", Type: models.StatusInfo}
+		return
+	}
+	m.panes = append(m.panes[:m.activePane], m.panes[m.activePane+1:]...)
+	idx := ui.Min(m.activePane, len(m.panes)-1)
+	if len(m.panes) == 1 {
+		m.panes = nil
+	}
+	if m.panes != nil {
+		m.loadPane(idx)
+	} else {
+		m.activePane = 0
+		m.adjustViewport()
+	}
+	m.status = models.StatusMsg{Message: "Pane closed", Type: models.StatusInfo}
+}
+
+// onlyPane closes every pane but the focused one
+func (m *Model) onlyPane() {
+	if len(m.panes) < 2 {
+		m.status = models.StatusMsg{Message: "Only one pane", Type: models.StatusInfo}
+		return
+	}
+	m.panes = nil
+	m.activePane = 0
+	m.adjustViewport()
+	m.status = models.StatusMsg{Message: "Other panes closed", Type: models.StatusInfo}
+}
+
+// windowCommand handles the key after the Ctrl+W window prefix
+func (m *Model) windowCommand(key string) {
+	m.awaitingWindow = false
+	switch key {
+	case "s", "S", "ctrl+s":
+		m.splitWindow(false, -1)
+	case "v", "ctrl+v":
+		m.splitWindow(true, -1)
+	case "w", "ctrl+w", "j", "l", "down", "right":
+		m.cyclePane(1)
+	case "W", "k", "h", "up", "left":
+		m.cyclePane(-1)
+	case "q", "c":
+		m.closePane()
+	case "o":
+		m.onlyPane()
+	default:
+		m.status = models.StatusMsg{Message: "Window keys: s split • v vsplit • w next • W prev • q close • o only", Type: models.StatusWarning}
+	}
+}
+
+// paneFits reports whether n panes leave every pane at least one row and a
+// narrow column
+func (m Model) paneFits(n int, vertical bool) bool {
+	if vertical {
+		return (m.width-(n-1))/n >= m.gutterWidth()+1+minScrollWidth
+	}
+	return (m.height-8-(n-1))/n >= 2
+}
+
+// paneRects lays out the panes: stacked ones share the table's lines, each
+// with its own header and a divider line between them; side by side ones
+// share the width with a divider column between them
+func (m Model) paneRects() []paneRect {
+	lines := ui.Max(1, m.height-9)
+	n := len(m.panes)
+	if n < 2 {
+		return []paneRect{{x: 0, y: headerLine, width: m.width, lines: lines}}
+	}
+
+	rects := make([]paneRect, n)
+	if m.splitVertical {
+		width := m.width - (n - 1)
+		x := 0
+		for i := range rects {
+			w := width / n
+			if i < width%n {
+				w++
+			}
+			rects[i] = paneRect{x: x, y: headerLine, width: ui.Max(1, w), lines: lines}
+			x += w + 1
+		}
+		return rects
+	}
+
+	// Lines for headers and rows once the dividers are taken out
+	total := lines + 1 - (n - 1)
+	y := headerLine
+	for i := range rects {
+		h := total / n
+		if i < total%n {
+			h++
+		}
+		rects[i] = paneRect{x: 0, y: y, width: m.width, lines: ui.Max(1, h-1)}
+		y += h + 1
+	}
+	return rects
+}
+
+// paneView returns a copy of the model with pane idx in focus, for drawing
+// a pane that does not have focus
+func (m Model) paneView(idx int) Model {
+	view := m
+	view.panes = append([]pane(nil), m.panes...)
+	view.storePane()
+
+	p := view.panes[idx]
+	view.activePane = idx
+	view.currentSheet = ui.Min(p.currentSheet, len(m.sheets)-1)
+	sheet := view.sheets[view.currentSheet]
+	view.cursorRow = ui.Min(p.cursorRow, ui.Max(0, sheet.MaxRows-1))
+	view.cursorCol = ui.Min(p.cursorCol, ui.Max(0, sheet.MaxCols-1))
+	view.offsetRow, view.offsetCol = p.offsetRow, p.offsetCol
+	view.selectStart, view.selectEnd, view.isSelecting = p.selectStart, p.selectEnd, p.isSelecting
+	if view.currentSheet != m.currentSheet {
+		view.rowView = view.sheetRowView(view.currentSheet)
+	}
+	view.unfocused = true
+	return view
+}
+
+// renderPanes draws the grid of every pane in its place on screen
+func (m Model) renderPanes() string {
+	if len(m.panes) < 2 {
+		return m.renderTable()
+	}
+
+	rects := m.paneRects()
+	blocks := make([]string, len(m.panes))
+	for i, rect := range rects {
+		view := m
+		if i != m.activePane {
+			view = m.paneView(i)
+		}
+		block := strings.TrimSuffix(view.renderTable(), "\n")
+
+		// Clip and pad the grid to exactly its pane
+		block = lipgloss.NewStyle().MaxWidth(rect.width).MaxHeight(rect.lines + 1).Render(block)
+		blocks[i] = lipgloss.NewStyle().Width(rect.width).Height(rect.lines + 1).Render(block)
+	}
+
+	if m.splitVertical {
+		divider := m.styles.Separator.Render(strings.TrimSuffix(strings.Repeat("┃\n", rects[0].lines+1), "\n"))
+		parts := make([]string, 0, 2*len(blocks))
+		for i, block := range blocks {
+			if i > 0 {
+				parts = append(parts, divider)
+			}
+			parts = append(parts, block)
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, parts...) + "\n"
+	}
+
+	var b strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			b.WriteString(m.styles.Separator.Render(strings.Repeat("━", ui.Max(0, m.width))))
+			b.WriteString("\n")
+		}
+		b.WriteString(block)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// activeRect returns the screen area of the focused pane
+func (m Model) activeRect() paneRect {
+	rects := m.paneRects()
+	return rects[ui.Min(m.activePane, len(rects)-1)]
+}
+
+// paneAt returns the pane drawn at screen position x, y
+func (m Model) paneAt(x, y int) (int, bool) {
+	for i, rect := range m.paneRects() {
+		if x >= rect.x && x < rect.x+rect.width && y >= rect.y && y <= rect.y+rect.lines {
+			return i, true
+		}
+	}
+	return 0, false
+}
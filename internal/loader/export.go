@@ -0,0 +1,297 @@
+package loader
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// ExportFunc writes a sheet to w in a specific format
+type ExportFunc func(w io.Writer, sheet models.Sheet) error
+
+// exporters maps a lowercase file extension (with leading dot) to its exporter
+var exporters = map[string]ExportFunc{
+	".csv":      writeCSV,
+	".tsv":      writeTSV,
+	".json":     writeJSON,
+	".yaml":     writeYAML,
+	".yml":      writeYAML,
+	".md":       writeMarkdown,
+	".markdown": writeMarkdown,
+	".html":     writeHTML,
+	".htm":      writeHTML,
+	".tex":      writeLaTeX,
+	".sql":      writeSQL,
+}
+
+// RegisterExporter registers an exporter for a file extension such as ".xml".
+// Registering an existing extension replaces its exporter.
+func RegisterExporter(ext string, fn ExportFunc) {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	exporters[ext] = fn
+}
+
+// ExportFormats returns the registered export extensions in sorted order
+func ExportFormats() []string {
+	exts := []string{".db", ".sqlite"}
+	for ext := range exporters {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// CanExport reports whether filename has a registered export extension
+func CanExport(filename string) bool {
+	_, ok := exporters[strings.ToLower(filepath.Ext(filename))]
+	return ok || IsSQLiteFile(filename)
+}
+
+// Export writes a sheet to filename using the exporter registered for its
+// extension. SQLite targets receive a new database with a single table.
+func Export(sheet models.Sheet, filename string) error {
+	if IsSQLiteFile(filename) {
+		return ExportSQLite([]models.Sheet{sheet}, filename)
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	fn, ok := exporters[ext]
+	if !ok {
+		return fmt.Errorf("unsupported export format: %s (supported: %s)", ext, strings.Join(ExportFormats(), ", "))
+	}
+	return exportFile(filename, fn, sheet)
+}
+
+// ExportTo writes a sheet to w in the format registered for ext
+func ExportTo(w io.Writer, sheet models.Sheet, ext string) error {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	fn, ok := exporters[ext]
+	if !ok {
+		return fmt.Errorf("unsupported export format: %s (supported: %s)", ext, strings.Join(ExportFormats(), ", "))
+	}
+	return fn(w, sheet)
+}
+
+// ExportToCSV exports a sheet to CSV format
+func ExportToCSV(sheet models.Sheet, filename string) error {
+	return exportFile(filename, writeCSV, sheet)
+}
+
+// ExportToJSON exports a sheet to JSON format
+func ExportToJSON(sheet models.Sheet, filename string) error {
+	return exportFile(filename, writeJSON, sheet)
+}
+
+// exportFile creates filename and writes the sheet into it with fn
+func exportFile(filename string, fn ExportFunc, sheet models.Sheet) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to close file: %v\n", closeErr)
+		}
+	}()
+
+	w := bufio.NewWriter(file)
+	if err := fn(w, sheet); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	return nil
+}
+
+// writeCSV writes the sheet values as comma separated values
+func writeCSV(w io.Writer, sheet models.Sheet) error {
+	return writeDelimited(w, sheet, ',')
+}
+
+// writeTSV writes the sheet values as tab separated values
+func writeTSV(w io.Writer, sheet models.Sheet) error {
+	return writeDelimited(w, sheet, '\t')
+}
+
+// writeDelimited writes the sheet values using the given field delimiter
+func writeDelimited(w io.Writer, sheet models.Sheet, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	for _, row := range sheet.Rows {
+		record := make([]string, 0, len(row))
+		for _, cell := range row {
+			record = append(record, cell.Value)
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("CSV writer error: %w", err)
+	}
+
+	return nil
+}
+
+// writeJSON writes the data rows as an array of objects keyed by the header row
+func writeJSON(w io.Writer, sheet models.Sheet) error {
+	if len(sheet.Rows) == 0 {
+		return fmt.Errorf("sheet is empty")
+	}
+
+	headers := columnHeaders(sheet)
+	data := make([]map[string]string, 0, len(sheet.Rows)-1)
+
+	for i := 1; i < len(sheet.Rows); i++ {
+		record := make(map[string]string)
+		for j, cell := range sheet.Rows[i] {
+			record[headers[j]] = cell.Value
+		}
+		data = append(data, record)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	return nil
+}
+
+// writeYAML writes the data rows as a YAML sequence of mappings keyed by the header row
+func writeYAML(w io.Writer, sheet models.Sheet) error {
+	if len(sheet.Rows) == 0 {
+		return fmt.Errorf("sheet is empty")
+	}
+
+	headers := columnHeaders(sheet)
+	cols := sheetWidth(sheet)
+
+	if len(sheet.Rows) == 1 {
+		_, err := io.WriteString(w, "[]\n")
+		return err
+	}
+
+	for i := 1; i < len(sheet.Rows); i++ {
+		values := rowValues(sheet.Rows[i], cols)
+		for j, value := range values {
+			prefix := "  "
+			if j == 0 {
+				prefix = "- "
+			}
+			if _, err := fmt.Fprintf(w, "%s%s: %s\n", prefix, yamlQuote(headers[j]), yamlQuote(value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// yamlQuote returns s as a double-quoted YAML scalar.
+// JSON string literals are valid YAML, so encoding/json does the escaping.
+func yamlQuote(s string) string {
+	quoted, err := json.Marshal(s)
+	if err != nil {
+		return `""`
+	}
+	return string(quoted)
+}
+
+// columnHeaders returns a header name for every column, using the first row
+// where available and col_N for blank or missing header cells
+func columnHeaders(sheet models.Sheet) []string {
+	cols := sheetWidth(sheet)
+	headers := make([]string, cols)
+	for j := range headers {
+		headers[j] = fmt.Sprintf("col_%d", j)
+		if len(sheet.Rows) > 0 && j < len(sheet.Rows[0]) && sheet.Rows[0][j].Value != "" {
+			headers[j] = sheet.Rows[0][j].Value
+		}
+	}
+	return headers
+}
+
+// sheetWidth returns the number of columns needed to hold every row of the sheet
+func sheetWidth(sheet models.Sheet) int {
+	cols := sheet.MaxCols
+	for _, row := range sheet.Rows {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+	return cols
+}
+
+// rowValues returns the values of row padded or trimmed to exactly cols entries
+func rowValues(row []models.Cell, cols int) []string {
+	values := make([]string, cols)
+	for j := 0; j < cols && j < len(row); j++ {
+		values[j] = row[j].Value
+	}
+	return values
+}
+
+// SubSheet returns a copy of sheet restricted to the given row indices and the
+// inclusive column range startCol..endCol. Cells are renumbered from zero so the
+// result can be passed to any exporter.
+func SubSheet(sheet models.Sheet, rows []int, startCol, endCol int) models.Sheet {
+	if startCol < 0 {
+		startCol = 0
+	}
+	cols := endCol - startCol + 1
+	if cols < 0 {
+		cols = 0
+	}
+
+	sub := models.Sheet{
+		Name:    sheet.Name,
+		MaxRows: len(rows),
+		MaxCols: cols,
+		Rows:    make([][]models.Cell, 0, len(rows)),
+	}
+
+	for i, r := range rows {
+		row := make([]models.Cell, cols)
+		for j := range row {
+			row[j] = models.Cell{Row: i, Col: j}
+			if r >= 0 && r < len(sheet.Rows) && startCol+j < len(sheet.Rows[r]) {
+				src := sheet.Rows[r][startCol+j]
+				row[j].Value = src.Value
+				row[j].Formula = src.Formula
+			}
+		}
+		sub.Rows = append(sub.Rows, row)
+	}
+
+	for col, width := range sheet.ColWidths {
+		if col >= startCol && col <= endCol {
+			if sub.ColWidths == nil {
+				sub.ColWidths = make(map[int]int)
+			}
+			sub.ColWidths[col-startCol] = width
+		}
+	}
+
+	return sub
+}
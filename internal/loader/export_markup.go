@@ -0,0 +1,175 @@
+package loader
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/internal/theme"
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// writeMarkdown writes the sheet as a GitHub flavored Markdown table.
+// The first row becomes the table header.
+func writeMarkdown(w io.Writer, sheet models.Sheet) error {
+	cols := sheetWidth(sheet)
+	if len(sheet.Rows) == 0 || cols == 0 {
+		return fmt.Errorf("sheet is empty")
+	}
+
+	var b strings.Builder
+	writeRow := func(values []string) {
+		b.WriteString("|")
+		for _, value := range values {
+			b.WriteString(" ")
+			b.WriteString(markdownEscape(value))
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(rowValues(sheet.Rows[0], cols))
+	b.WriteString("|")
+	for j := 0; j < cols; j++ {
+		if isNumericColumn(sheet, j) {
+			b.WriteString(" ---: |")
+		} else {
+			b.WriteString(" --- |")
+		}
+	}
+	b.WriteString("\n")
+
+	for _, row := range sheet.Rows[1:] {
+		writeRow(rowValues(row, cols))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscape escapes pipes and line breaks so a value stays inside its table cell
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	s = strings.ReplaceAll(s, "\n", "<br>")
+	return s
+}
+
+// writeHTML writes the sheet as a standalone HTML document styled with the current theme
+func writeHTML(w io.Writer, sheet models.Sheet) error {
+	cols := sheetWidth(sheet)
+	if len(sheet.Rows) == 0 || cols == 0 {
+		return fmt.Errorf("sheet is empty")
+	}
+
+	t := theme.GetCurrentTheme()
+	title := html.EscapeString(sheet.Name)
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", title)
+	b.WriteString("<style>\n")
+	fmt.Fprintf(&b, "body { background: %s; color: %s; font-family: ui-monospace, monospace; margin: 2em; }\n", t.Background, t.Text)
+	fmt.Fprintf(&b, "h1 { color: %s; font-size: 1.2em; }\n", t.Primary)
+	fmt.Fprintf(&b, "table { border-collapse: collapse; }\n")
+	fmt.Fprintf(&b, "th, td { border: 1px solid %s; padding: 0.25em 0.75em; text-align: left; }\n", t.Border)
+	fmt.Fprintf(&b, "th { background: %s; color: %s; }\n", t.Border, t.Secondary)
+	fmt.Fprintf(&b, "tr:nth-child(even) td { background: %s; }\n", t.RowHighlight)
+	b.WriteString("td.num { text-align: right; }\n")
+	b.WriteString("</style>\n</head>\n<body>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<table>\n<thead>\n<tr>", title)
+	for _, value := range rowValues(sheet.Rows[0], cols) {
+		fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(value))
+	}
+	b.WriteString("</tr>\n</thead>\n<tbody>\n")
+
+	numeric := make([]bool, cols)
+	for j := range numeric {
+		numeric[j] = isNumericColumn(sheet, j)
+	}
+
+	for _, row := range sheet.Rows[1:] {
+		b.WriteString("<tr>")
+		for j, value := range rowValues(row, cols) {
+			value = strings.ReplaceAll(html.EscapeString(value), "\n", "<br>")
+			if numeric[j] {
+				fmt.Fprintf(&b, "<td class=\"num\">%s</td>", value)
+			} else {
+				fmt.Fprintf(&b, "<td>%s</td>", value)
+			}
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody>\n</table>\n</body>\n</html>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeLaTeX writes the sheet as a LaTeX tabular environment
+func writeLaTeX(w io.Writer, sheet models.Sheet) error {
+	cols := sheetWidth(sheet)
+	if len(sheet.Rows) == 0 || cols == 0 {
+		return fmt.Errorf("sheet is empty")
+	}
+
+	spec := make([]string, cols)
+	for j := range spec {
+		spec[j] = "l"
+		if isNumericColumn(sheet, j) {
+			spec[j] = "r"
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\\begin{tabular}{|%s|}\n\\hline\n", strings.Join(spec, "|"))
+	for i, row := range sheet.Rows {
+		values := rowValues(row, cols)
+		for j := range values {
+			values[j] = latexEscape(values[j])
+		}
+		if i == 0 {
+			for j := range values {
+				if values[j] != "" {
+					values[j] = "\\textbf{" + values[j] + "}"
+				}
+			}
+		}
+		b.WriteString(strings.Join(values, " & "))
+		b.WriteString(" \\\\\n")
+		if i == 0 {
+			b.WriteString("\\hline\n")
+		}
+	}
+	b.WriteString("\\hline\n\\end{tabular}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// latexReplacer escapes characters with special meaning in LaTeX
+var latexReplacer = strings.NewReplacer(
+	"\\", "\\textbackslash{}",
+	"&", "\\&",
+	"%", "\\%",
+	"$", "\\$",
+	"#", "\\#",
+	"_", "\\_",
+	"{", "\\{",
+	"}", "\\}",
+	"~", "\\textasciitilde{}",
+	"^", "\\textasciicircum{}",
+	"\n", " ",
+)
+
+// latexEscape escapes s for use inside a tabular cell
+func latexEscape(s string) string {
+	return latexReplacer.Replace(s)
+}
+
+// isNumericColumn reports whether every non-empty data cell in column col is a number
+func isNumericColumn(sheet models.Sheet, col int) bool {
+	return inferColumnType(sheet, col) != ColumnText
+}
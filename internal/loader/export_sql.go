@@ -0,0 +1,185 @@
+package loader
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// ColumnType is the storage type inferred for a column of data
+type ColumnType int
+
+const (
+	ColumnText ColumnType = iota
+	ColumnInteger
+	ColumnReal
+)
+
+// SQL returns the SQL type name for the column type
+func (t ColumnType) SQL() string {
+	switch t {
+	case ColumnInteger:
+		return "INTEGER"
+	case ColumnReal:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// InferColumnTypes infers a type for every column from the data rows.
+// The first row is treated as the header and ignored.
+func InferColumnTypes(sheet models.Sheet) []ColumnType {
+	types := make([]ColumnType, sheetWidth(sheet))
+	for j := range types {
+		types[j] = inferColumnType(sheet, j)
+	}
+	return types
+}
+
+// inferColumnType returns the narrowest type that fits every non-empty data cell
+// in column col. Columns without any data are TEXT.
+func inferColumnType(sheet models.Sheet, col int) ColumnType {
+	result := ColumnText
+	seen := false
+
+	for i := 1; i < len(sheet.Rows); i++ {
+		if col >= len(sheet.Rows[i]) {
+			continue
+		}
+		value := strings.TrimSpace(sheet.Rows[i][col].Value)
+		if value == "" {
+			continue
+		}
+
+		t := valueType(value)
+		if t == ColumnText {
+			return ColumnText
+		}
+		if !seen || t == ColumnReal {
+			result = t
+		}
+		seen = true
+	}
+
+	return result
+}
+
+// valueType classifies a single trimmed value
+func valueType(value string) ColumnType {
+	// Keep identifiers such as zip codes and phone numbers as text
+	if len(value) > 1 && value[0] == '0' && value[1] != '.' {
+		return ColumnText
+	}
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return ColumnInteger
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return ColumnReal
+	}
+	return ColumnText
+}
+
+// writeSQL writes a CREATE TABLE statement followed by one INSERT per data row.
+// The first row provides the column names.
+func writeSQL(w io.Writer, sheet models.Sheet) error {
+	cols := sheetWidth(sheet)
+	if len(sheet.Rows) == 0 || cols == 0 {
+		return fmt.Errorf("sheet is empty")
+	}
+
+	table := SQLIdentifier(sheet.Name)
+	names := SQLColumnNames(sheet)
+	types := InferColumnTypes(sheet)
+
+	defs := make([]string, cols)
+	quoted := make([]string, cols)
+	for j := range defs {
+		quoted[j] = QuoteSQLIdentifier(names[j])
+		defs[j] = "  " + quoted[j] + " " + types[j].SQL()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n%s\n);\n\n", QuoteSQLIdentifier(table), strings.Join(defs, ",\n"))
+
+	columnList := strings.Join(quoted, ", ")
+	for _, row := range sheet.Rows[1:] {
+		values := rowValues(row, cols)
+		literals := make([]string, cols)
+		for j, value := range values {
+			literals[j] = sqlLiteral(value, types[j])
+		}
+		fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES (%s);\n", QuoteSQLIdentifier(table), columnList, strings.Join(literals, ", "))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// sqlLiteral renders a value as a SQL literal for a column of type t
+func sqlLiteral(value string, t ColumnType) string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return "NULL"
+	}
+	if t != ColumnText {
+		return trimmed
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// SQLColumnNames returns unique SQL-safe column names derived from the header row
+func SQLColumnNames(sheet models.Sheet) []string {
+	headers := columnHeaders(sheet)
+	names := make([]string, len(headers))
+	used := make(map[string]int)
+
+	for j, header := range headers {
+		name := SQLIdentifier(header)
+		key := strings.ToLower(name)
+		if n, ok := used[key]; ok {
+			used[key] = n + 1
+			name = fmt.Sprintf("%s_%d", name, n+1)
+			key = strings.ToLower(name)
+		}
+		used[key] = 1
+		names[j] = name
+	}
+
+	return names
+}
+
+// SQLIdentifier converts an arbitrary name into a plain SQL identifier made of
+// letters, digits and underscores that does not start with a digit
+func SQLIdentifier(name string) string {
+	var b strings.Builder
+	lastUnderscore := false
+	for _, r := range strings.TrimSpace(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			lastUnderscore = false
+		} else if !lastUnderscore {
+			b.WriteByte('_')
+			lastUnderscore = true
+		}
+	}
+
+	id := strings.Trim(b.String(), "_")
+	if id == "" {
+		return "data"
+	}
+	if unicode.IsDigit([]rune(id)[0]) {
+		id = "_" + id
+	}
+	return id
+}
+
+// QuoteSQLIdentifier quotes an identifier with double quotes
+func QuoteSQLIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
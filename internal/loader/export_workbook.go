@@ -0,0 +1,146 @@
+package loader
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// ExportWorkbook exports every sheet in one go. A target ending in .json is
+// written as a single JSON object keyed by sheet name and a SQLite target
+// receives one table per sheet; any other target is treated as a directory
+// that receives one CSV file per sheet.
+// It returns the paths of the files written.
+func ExportWorkbook(sheets []models.Sheet, target string) ([]string, error) {
+	if IsSQLiteFile(target) {
+		if err := ExportSQLite(sheets, target); err != nil {
+			return nil, err
+		}
+		return []string{target}, nil
+	}
+	if strings.EqualFold(filepath.Ext(target), ".json") {
+		if err := ExportWorkbookJSON(sheets, target); err != nil {
+			return nil, err
+		}
+		return []string{target}, nil
+	}
+	return ExportWorkbookDir(sheets, target, ".csv")
+}
+
+// ExportWorkbookDir writes each sheet to its own file in dir using the exporter
+// registered for ext. File names are derived from the sheet names.
+func ExportWorkbookDir(sheets []models.Sheet, dir, ext string) ([]string, error) {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	fn, ok := exporters[ext]
+	if !ok {
+		return nil, fmt.Errorf("unsupported export format: %s (supported: %s)", ext, strings.Join(ExportFormats(), ", "))
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	written := make([]string, 0, len(sheets))
+	used := make(map[string]bool)
+
+	for i, sheet := range sheets {
+		base := SanitizeFilename(sheet.Name)
+		if base == "" {
+			base = fmt.Sprintf("sheet%d", i+1)
+		}
+		name := base
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[strings.ToLower(name)] = true
+
+		path := filepath.Join(dir, name+ext)
+		if err := exportFile(path, fn, sheet); err != nil {
+			return written, fmt.Errorf("sheet %s: %w", sheet.Name, err)
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
+
+// ExportWorkbookJSON writes all sheets to a single JSON object keyed by sheet
+// name, in workbook order. Each value has the same shape as a single-sheet
+// JSON export.
+func ExportWorkbookJSON(sheets []models.Sheet, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create JSON file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to close file: %v\n", closeErr)
+		}
+	}()
+
+	w := bufio.NewWriter(file)
+	if err := writeWorkbookJSON(w, sheets); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	return nil
+}
+
+// writeWorkbookJSON writes the sheets as one JSON object, preserving sheet order
+func writeWorkbookJSON(w io.Writer, sheets []models.Sheet) error {
+	var b strings.Builder
+	b.WriteString("{")
+
+	for i, sheet := range sheets {
+		key, err := json.Marshal(sheet.Name)
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+
+		var records strings.Builder
+		if len(sheet.Rows) == 0 {
+			records.WriteString("[]")
+		} else if err := writeJSON(&records, sheet); err != nil {
+			return fmt.Errorf("sheet %s: %w", sheet.Name, err)
+		}
+
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  ")
+		b.Write(key)
+		b.WriteString(": ")
+		b.WriteString(strings.ReplaceAll(strings.TrimRight(records.String(), "\n"), "\n", "\n  "))
+	}
+
+	b.WriteString("\n}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// SanitizeFilename turns a sheet name into a portable file name by replacing
+// path separators and characters that are reserved on common file systems
+func SanitizeFilename(name string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(name) {
+		switch {
+		case r < 0x20, strings.ContainsRune(`/\:*?"<>|`, r):
+			b.WriteByte('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Trim(b.String(), ". ")
+}
@@ -2,10 +2,11 @@ package loader
 
 import (
 	"encoding/csv"
-	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/CodeOne45/vex-tui/pkg/models"
@@ -21,11 +22,61 @@ func LoadFile(filename string) ([]models.Sheet, error) {
 		return loadExcel(filename)
 	case ".csv":
 		return loadCSV(filename)
+	case ".tsv", ".json":
+		return loadText(filename)
+	case ".db", ".sqlite", ".sqlite3":
+		return loadSQLite(filename)
 	default:
-		return nil, fmt.Errorf("unsupported file format: %s (supported: .xlsx, .xlsm, .xls, .csv)", ext)
+		return nil, fmt.Errorf("unsupported file format: %s (supported: .xlsx, .xlsm, .xls, .csv, .tsv, .json, .db, .sqlite)", ext)
 	}
 }
 
+// Formats of files that are not text, as returned by FileFormat
+const (
+	FormatSQLite = "sqlite"
+	FormatXLSX   = "xlsx"
+)
+
+// FileFormat returns the save format for a filename: FormatCSV, FormatTSV,
+// FormatJSON, FormatSQLite or FormatXLSX
+func FileFormat(filename string) string {
+	switch {
+	case strings.EqualFold(filepath.Ext(filename), ".csv"):
+		return FormatCSV
+	case strings.EqualFold(filepath.Ext(filename), ".tsv"):
+		return FormatTSV
+	case strings.EqualFold(filepath.Ext(filename), ".json"):
+		return FormatJSON
+	case IsSQLiteFile(filename):
+		return FormatSQLite
+	default:
+		return FormatXLSX
+	}
+}
+
+// LoadAllRows fetches any rows of a lazily loaded sheet that are not yet in memory
+func LoadAllRows(sheet *models.Sheet) error {
+	if sheet.Source == nil || sheet.Source.Complete() {
+		return nil
+	}
+	return sheet.Source.LoadRows(sheet, sheet.MaxRows)
+}
+
+// CloseSheets releases the sources of sheets loaded on demand, returning the
+// first error
+func CloseSheets(sheets []models.Sheet) error {
+	var first error
+	for _, sheet := range sheets {
+		if sheet.Source == nil {
+			continue
+		}
+		if err := sheet.Source.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
 // loadExcel loads an Excel file
 func loadExcel(filename string) ([]models.Sheet, error) {
 	f, err := excelize.OpenFile(filename)
@@ -45,6 +96,7 @@ func loadExcel(filename string) ([]models.Sheet, error) {
 	}
 
 	sheets := make([]models.Sheet, 0, len(sheetList))
+	names := f.GetDefinedName()
 
 	for _, sheetName := range sheetList {
 		rows, err := f.GetRows(sheetName)
@@ -75,6 +127,9 @@ func loadExcel(filename string) ([]models.Sheet, error) {
 					Row:     rowIdx,
 					Col:     colIdx,
 				}
+				if linked, target, err := f.GetCellHyperLink(sheetName, cellRef); err == nil && linked && target != "" {
+					cell.Link = linkOf(target)
+				}
 				cellRow = append(cellRow, cell)
 
 				if colIdx+1 > sheet.MaxCols {
@@ -84,12 +139,172 @@ func loadExcel(filename string) ([]models.Sheet, error) {
 			sheet.Rows = append(sheet.Rows, cellRow)
 		}
 
+		// Comments are optional; a sheet whose comments cannot be read still
+		// opens with its data
+		if comments, err := f.GetComments(sheetName); err == nil {
+			addComments(&sheet, comments)
+		}
+
+		sheet.ColWidths, sheet.HiddenCols = colLayout(f, sheetName, sheet.MaxCols)
+		sheet.RowHeights, sheet.HiddenRows = rowLayout(f, sheetName)
+		sheet.RowLevels, sheet.ColLevels = outlineLevels(f, sheetName, sheet.MaxRows, sheet.MaxCols)
+
+		// Data validation is optional like comments
+		if dvs, err := f.GetDataValidations(sheetName); err == nil {
+			sheet.Validations = sheetValidations(dvs, names, sheetName)
+		}
+
+		// Frozen panes of the sheet view; split panes are not frozen
+		if panes, err := f.GetPanes(sheetName); err == nil && panes.Freeze {
+			sheet.FreezeRows, sheet.FreezeCols = panes.YSplit, panes.XSplit
+		}
+
 		sheets = append(sheets, sheet)
 	}
 
+	// Autofilters are optional; a workbook whose filters cannot be read
+	// still opens unfiltered
+	if autoFilters, err := readAutoFilters(filename); err == nil {
+		for i := range sheets {
+			if af, ok := autoFilters[sheets[i].Name]; ok {
+				sheets[i].Filters, sheets[i].FilterHeader = sheetFilters(af)
+			}
+		}
+	}
+
 	return sheets, nil
 }
 
+// addComments attaches comments to their cells, growing the sheet when a
+// comment sits outside the data. Excel starts a comment with its author's
+// name, which is left out of the text.
+func addComments(sheet *models.Sheet, comments []excelize.Comment) {
+	for _, comment := range comments {
+		col, row, err := excelize.CellNameToCoordinates(comment.Cell)
+		if err != nil {
+			continue
+		}
+		row, col = row-1, col-1
+
+		text := comment.Text
+		for _, run := range comment.Paragraph {
+			text += run.Text
+		}
+		if comment.Author != "" {
+			text = strings.TrimPrefix(text, comment.Author+":")
+		}
+
+		for len(sheet.Rows) <= row {
+			sheet.Rows = append(sheet.Rows, nil)
+		}
+		for len(sheet.Rows[row]) <= col {
+			sheet.Rows[row] = append(sheet.Rows[row], models.Cell{Row: row, Col: len(sheet.Rows[row])})
+		}
+		cell := &sheet.Rows[row][col]
+		cell.Comment = strings.TrimSpace(text)
+		cell.CommentAuthor = comment.Author
+		if row+1 > sheet.MaxRows {
+			sheet.MaxRows = row + 1
+		}
+		if col+1 > sheet.MaxCols {
+			sheet.MaxCols = col + 1
+		}
+	}
+}
+
+// excelDefaultColWidth is the width Excel gives columns when neither the
+// column nor the sheet sets one
+const excelDefaultColWidth = 9.140625
+
+// colLayout reads the widths of the columns a sheet sizes explicitly and
+// which columns it hides. Excel measures widths in characters, which map onto
+// terminal cells; columns left at the sheet's default width keep vex's
+// default.
+func colLayout(f *excelize.File, sheetName string, maxCols int) (map[int]int, map[int]bool) {
+	defaultWidth := excelDefaultColWidth
+	if props, err := f.GetSheetProps(sheetName); err == nil && props.DefaultColWidth != nil && *props.DefaultColWidth > 0 {
+		defaultWidth = *props.DefaultColWidth
+	}
+
+	widths := make(map[int]int)
+	hidden := make(map[int]bool)
+	for col := 1; col <= maxCols; col++ {
+		name, err := excelize.ColumnNumberToName(col)
+		if err != nil {
+			break
+		}
+		if visible, err := f.GetColVisible(sheetName, name); err == nil && !visible {
+			hidden[col-1] = true
+		}
+		width, err := f.GetColWidth(sheetName, name)
+		if err != nil || width == defaultWidth || width < 1 {
+			continue
+		}
+		widths[col-1] = int(math.Round(width))
+	}
+	return widths, hidden
+}
+
+// outlineLevels reads the outline levels of the grouped rows and columns of
+// a sheet
+func outlineLevels(f *excelize.File, sheetName string, maxRows, maxCols int) (map[int]int, map[int]int) {
+	rows := make(map[int]int)
+	for row := 1; row <= maxRows; row++ {
+		if level, err := f.GetRowOutlineLevel(sheetName, row); err == nil && level > 0 {
+			rows[row-1] = int(level)
+		}
+	}
+	cols := make(map[int]int)
+	for col := 1; col <= maxCols; col++ {
+		name, err := excelize.ColumnNumberToName(col)
+		if err != nil {
+			break
+		}
+		if level, err := f.GetColOutlineLevel(sheetName, name); err == nil && level > 0 {
+			cols[col-1] = int(level)
+		}
+	}
+	return rows, cols
+}
+
+// excelLineHeight is the height in points of a row holding one line of
+// Excel's default font
+const excelLineHeight = 15.0
+
+// rowLayout reads the heights of the rows a sheet sizes explicitly,
+// converted to lines of text, and which rows it hides
+func rowLayout(f *excelize.File, sheetName string) (map[int]int, map[int]bool) {
+	heights := make(map[int]int)
+	hidden := make(map[int]bool)
+	rows, err := f.Rows(sheetName)
+	if err != nil {
+		return heights, hidden
+	}
+	defer rows.Close()
+
+	defaultHeight := excelLineHeight
+	if props, err := f.GetSheetProps(sheetName); err == nil && props.DefaultRowHeight != nil && *props.DefaultRowHeight > 0 {
+		defaultHeight = *props.DefaultRowHeight
+	}
+	for row := 0; rows.Next(); row++ {
+		// Rows missing from the file report no height
+		opts := rows.GetRowOpts()
+		if opts.Hidden {
+			hidden[row] = true
+		}
+		height := opts.Height
+		if height <= 0 || height == defaultHeight {
+			continue
+		}
+		lines := int(math.Round(height / excelLineHeight))
+		if lines < 1 {
+			lines = 1
+		}
+		heights[row] = lines
+	}
+	return heights, hidden
+}
+
 // loadCSV loads a CSV file
 func loadCSV(filename string) ([]models.Sheet, error) {
 	file, err := os.Open(filename)
@@ -109,8 +324,13 @@ func loadCSV(filename string) ([]models.Sheet, error) {
 		return nil, fmt.Errorf("failed to read CSV: %w", err)
 	}
 
+	return []models.Sheet{recordsToSheet(filepath.Base(filename), records)}, nil
+}
+
+// recordsToSheet converts parsed delimited records into a sheet
+func recordsToSheet(name string, records [][]string) models.Sheet {
 	sheet := models.Sheet{
-		Name:    filepath.Base(filename),
+		Name:    name,
 		MaxRows: len(records),
 		Rows:    make([][]models.Cell, 0, len(records)),
 	}
@@ -132,101 +352,64 @@ func loadCSV(filename string) ([]models.Sheet, error) {
 		sheet.Rows = append(sheet.Rows, cellRow)
 	}
 
-	return []models.Sheet{sheet}, nil
+	return sheet
 }
 
-// ExportToCSV exports a sheet to CSV format
-func ExportToCSV(sheet models.Sheet, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create CSV file: %w", err)
-	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to close file: %v\n", closeErr)
-		}
-	}()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	for _, row := range sheet.Rows {
-		record := make([]string, 0, len(row))
-		for _, cell := range row {
-			record = append(record, cell.Value)
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("failed to write CSV row: %w", err)
+// CompileSearch builds the pattern for a search term. Plain terms match
+// literally; matching ignores case unless MatchCase is set, and WholeCell
+// requires the pattern to match the entire value.
+func CompileSearch(term string, opts models.SearchOptions) (*regexp.Regexp, error) {
+	pattern := regexp.QuoteMeta(term)
+	if opts.Regex {
+		// Check the pattern alone so errors quote what the user typed
+		if _, err := regexp.Compile(term); err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
 		}
+		pattern = term
 	}
-
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("CSV writer error: %w", err)
+	if opts.WholeCell {
+		pattern = "^(?:" + pattern + ")$"
 	}
-
-	return nil
-}
-
-// ExportToJSON exports a sheet to JSON format
-func ExportToJSON(sheet models.Sheet, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create JSON file: %w", err)
-	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to close file: %v\n", closeErr)
-		}
-	}()
-
-	// Use first row as headers if available
-	if len(sheet.Rows) == 0 {
-		return fmt.Errorf("sheet is empty")
-	}
-
-	data := make([]map[string]string, 0, len(sheet.Rows)-1)
-	headers := sheet.Rows[0]
-
-	for i := 1; i < len(sheet.Rows); i++ {
-		row := sheet.Rows[i]
-		record := make(map[string]string)
-
-		for j, cell := range row {
-			headerKey := fmt.Sprintf("col_%d", j)
-			if j < len(headers) && headers[j].Value != "" {
-				headerKey = headers[j].Value
-			}
-			record[headerKey] = cell.Value
-		}
-		data = append(data, record)
+	if !opts.MatchCase {
+		pattern = "(?i)" + pattern
 	}
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(data); err != nil {
-		return fmt.Errorf("failed to encode JSON: %w", err)
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
 	}
-
-	return nil
+	return re, nil
 }
 
-// SearchSheet searches for a term in the sheet
-func SearchSheet(sheet models.Sheet, term string) []models.Cell {
+// SearchSheet searches for a term in the values and/or formulas of the sheet
+func SearchSheet(sheet models.Sheet, term string, opts models.SearchOptions) ([]models.Cell, error) {
 	if term == "" {
-		return nil
+		return nil, nil
 	}
 
-	term = strings.ToLower(term)
-	results := make([]models.Cell, 0)
+	re, err := CompileSearch(term, opts)
+	if err != nil {
+		return nil, err
+	}
 
+	results := make([]models.Cell, 0)
 	for _, row := range sheet.Rows {
 		for _, cell := range row {
-			if strings.Contains(strings.ToLower(cell.Value), term) ||
-				strings.Contains(strings.ToLower(cell.Formula), term) {
+			if CellMatches(re, cell, opts.Target) {
 				results = append(results, cell)
 			}
 		}
 	}
 
-	return results
+	return results, nil
+}
+
+// CellMatches reports whether the searched part of a cell matches re
+func CellMatches(re *regexp.Regexp, cell models.Cell, target models.SearchTarget) bool {
+	switch target {
+	case models.SearchValues:
+		return re.MatchString(cell.Value)
+	case models.SearchFormulas:
+		return cell.Formula != "" && re.MatchString(cell.Formula)
+	}
+	return re.MatchString(cell.Value) || (cell.Formula != "" && re.MatchString(cell.Formula))
 }
@@ -0,0 +1,88 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// QueryTableName returns the table name a sheet is queried by: its name as a
+// SQL identifier, without the extension of a CSV, TSV or JSON file name
+func QueryTableName(sheetName string) string {
+	switch strings.ToLower(filepath.Ext(sheetName)) {
+	case ".csv", ".tsv", ".json":
+		sheetName = strings.TrimSuffix(sheetName, filepath.Ext(sheetName))
+	}
+	return SQLIdentifier(sheetName)
+}
+
+// QueryTables returns the table name of each sheet in a query, or "" for
+// sheets without data
+func QueryTables(sheets []models.Sheet) []string {
+	return sqliteTableNames(sheets, QueryTableName)
+}
+
+// QuerySheets runs a SQL query against the sheets, each loaded as a table
+// into an in-memory SQLite database. The first row of a sheet names the
+// columns. The result is returned as a read-only sheet with a header row.
+func QuerySheets(sheets []models.Sheet, query string) (models.Sheet, error) {
+	return QueryNamedSheets(sheets, QueryTables(sheets), query)
+}
+
+// QueryNamedSheets runs a SQL query like QuerySheets, loading each sheet as
+// the table named in tables; sheets with an empty name are left out
+func QueryNamedSheets(sheets []models.Sheet, tables []string, query string) (models.Sheet, error) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return models.Sheet{}, fmt.Errorf("failed to open query database: %w", err)
+	}
+	defer db.Close()
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+
+	if err := writeSQLiteTables(db, sheets, tables); err != nil {
+		return models.Sheet{}, err
+	}
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return models.Sheet{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return models.Sheet{}, fmt.Errorf("query failed: %w", err)
+	}
+	if len(columns) == 0 {
+		return models.Sheet{}, fmt.Errorf("query returned no columns")
+	}
+
+	records := [][]string{columns}
+	raw := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return models.Sheet{}, fmt.Errorf("query failed: %w", err)
+		}
+		record := make([]string, len(columns))
+		for j, v := range raw {
+			record[j] = sqliteValueString(v)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Sheet{}, fmt.Errorf("query failed: %w", err)
+	}
+
+	sheet := recordsToSheet("Query", records)
+	sheet.ReadOnly = true
+	sheet.Query = query
+	return sheet, nil
+}
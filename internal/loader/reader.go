@@ -0,0 +1,281 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/pkg/models"
+)
+
+// Text formats recognised by SniffFormat
+const (
+	FormatCSV  = "csv"
+	FormatTSV  = "tsv"
+	FormatJSON = "json"
+)
+
+// LoadReader reads delimited or JSON data from r, such as a pipe on stdin,
+// sniffing the format from the content. name is used as the sheet name.
+func LoadReader(r io.Reader, name string) ([]models.Sheet, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("input is empty")
+	}
+	return parseText(data, SniffFormat(data), name)
+}
+
+// loadText loads a TSV or JSON file, choosing the parser by extension
+func loadText(filename string) ([]models.Sheet, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	format := FormatTSV
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		format = FormatJSON
+	}
+	return parseText(data, format, filepath.Base(filename))
+}
+
+// SniffFormat guesses whether data is JSON, TSV or CSV. JSON is detected by a
+// leading bracket or brace; otherwise the delimiter that occurs most often in
+// the first line wins.
+func SniffFormat(data []byte) string {
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') && json.Valid(trimmed) {
+		return FormatJSON
+	}
+
+	firstLine := trimmed
+	if i := bytes.IndexByte(trimmed, '\n'); i >= 0 {
+		firstLine = trimmed[:i]
+	}
+	if bytes.Count(firstLine, []byte{'\t'}) > bytes.Count(firstLine, []byte{','}) {
+		return FormatTSV
+	}
+	return FormatCSV
+}
+
+// parseText parses data in the given text format into sheets
+func parseText(data []byte, format, name string) ([]models.Sheet, error) {
+	switch format {
+	case FormatJSON:
+		return parseJSON(data, name)
+	case FormatTSV:
+		return parseDelimited(data, '\t', name)
+	default:
+		return parseDelimited(data, ',', name)
+	}
+}
+
+// parseDelimited parses comma or tab separated data into a single sheet.
+// Rows may have differing numbers of fields.
+func parseDelimited(data []byte, delimiter rune, name string) ([]models.Sheet, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse input: %w", err)
+	}
+	return []models.Sheet{recordsToSheet(name, records)}, nil
+}
+
+// orderedObject is a JSON object that remembers the order of its keys
+type orderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// parseJSON parses JSON data into sheets. Supported shapes are an array of
+// objects (keys become the header row), an array of arrays, a single object,
+// and an object of arrays such as a multi-sheet export, which yields one sheet
+// per key.
+func parseJSON(data []byte, name string) ([]models.Sheet, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	value, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	if obj, ok := value.(orderedObject); ok && len(obj.keys) > 0 {
+		allArrays := true
+		for _, key := range obj.keys {
+			if _, isArray := obj.values[key].([]any); !isArray {
+				allArrays = false
+				break
+			}
+		}
+		if allArrays {
+			sheets := make([]models.Sheet, 0, len(obj.keys))
+			for _, key := range obj.keys {
+				sheets = append(sheets, recordsToSheet(key, jsonRecords(obj.values[key].([]any))))
+			}
+			return sheets, nil
+		}
+		value = []any{obj}
+	}
+
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse JSON: expected an array or object")
+	}
+	return []models.Sheet{recordsToSheet(name, jsonRecords(items))}, nil
+}
+
+// jsonRecords converts an array of objects or arrays into rows of text. Object
+// keys are collected in first-seen order to form the header row.
+func jsonRecords(items []any) [][]string {
+	var header []string
+	index := make(map[string]int)
+	hasObjects := false
+
+	for _, item := range items {
+		if obj, ok := item.(orderedObject); ok {
+			hasObjects = true
+			for _, key := range obj.keys {
+				if _, seen := index[key]; !seen {
+					index[key] = len(header)
+					header = append(header, key)
+				}
+			}
+		}
+	}
+
+	records := make([][]string, 0, len(items)+1)
+	if hasObjects {
+		records = append(records, header)
+	}
+
+	for _, item := range items {
+		switch v := item.(type) {
+		case orderedObject:
+			record := make([]string, len(header))
+			for _, key := range v.keys {
+				record[index[key]] = jsonText(v.values[key])
+			}
+			records = append(records, record)
+		case []any:
+			record := make([]string, len(v))
+			for j, elem := range v {
+				record[j] = jsonText(elem)
+			}
+			records = append(records, record)
+		default:
+			records = append(records, []string{jsonText(v)})
+		}
+	}
+
+	return records
+}
+
+// jsonText renders a decoded JSON value as cell text. Nested values are kept
+// as compact JSON.
+func jsonText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	default:
+		encoded, err := json.Marshal(plainJSON(v))
+		if err != nil {
+			return ""
+		}
+		return string(encoded)
+	}
+}
+
+// plainJSON converts ordered objects back into values encoding/json can marshal
+func plainJSON(v any) any {
+	switch v := v.(type) {
+	case orderedObject:
+		m := make(map[string]any, len(v.keys))
+		for _, key := range v.keys {
+			m[key] = plainJSON(v.values[key])
+		}
+		return m
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = plainJSON(elem)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// decodeOrdered decodes the next JSON value, keeping object key order
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '[':
+		items := []any{}
+		for dec.More() {
+			item, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return items, nil
+
+	case '{':
+		obj := orderedObject{values: make(map[string]any)}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyTok.(string)
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := obj.values[key]; !dup {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = value
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/CodeOne45/vex-tui/pkg/models"
 	"github.com/xuri/excelize/v2"
@@ -19,12 +20,13 @@ func SaveExcel(sheets []models.Sheet, filename string) error {
 	}()
 
 	for idx, sheet := range sheets {
-		var sheetName string
+		sheetName := sheet.Name
 		if idx == 0 {
-			sheetName = "Sheet1"
-			f.SetSheetName("Sheet1", sheet.Name)
+			// The new file's default sheet becomes the first sheet
+			if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+				return fmt.Errorf("failed to rename sheet to %s: %w", sheetName, err)
+			}
 		} else {
-			sheetName = sheet.Name
 			_, err := f.NewSheet(sheetName)
 			if err != nil {
 				return fmt.Errorf("failed to create sheet %s: %w", sheetName, err)
@@ -49,8 +51,91 @@ func SaveExcel(sheets []models.Sheet, filename string) error {
 						continue
 					}
 				}
+
+				if cell.Comment != "" {
+					if err := f.AddComment(sheetName, commentOf(cellRef, cell)); err != nil {
+						return fmt.Errorf("failed to add comment to %s!%s: %w", sheetName, cellRef, err)
+					}
+				}
+
+				if cell.Link != "" {
+					if err := setLink(f, sheetName, cellRef, cell.Link); err != nil {
+						return fmt.Errorf("failed to add link to %s!%s: %w", sheetName, cellRef, err)
+					}
+				}
+			}
+		}
+
+		for col, width := range sheet.ColWidths {
+			name, err := excelize.ColumnNumberToName(col + 1)
+			if err != nil || width <= 0 {
+				continue
+			}
+			if err := f.SetColWidth(sheetName, name, name, float64(width)); err != nil {
+				return fmt.Errorf("failed to set column width in %s: %w", sheetName, err)
+			}
+		}
+
+		for row, lines := range sheet.RowHeights {
+			if lines <= 0 {
+				continue
+			}
+			if err := f.SetRowHeight(sheetName, row+1, float64(lines)*excelLineHeight); err != nil {
+				return fmt.Errorf("failed to set row height in %s: %w", sheetName, err)
+			}
+		}
+
+		for col, hidden := range sheet.HiddenCols {
+			name, err := excelize.ColumnNumberToName(col + 1)
+			if err != nil || !hidden {
+				continue
+			}
+			if err := f.SetColVisible(sheetName, name, false); err != nil {
+				return fmt.Errorf("failed to hide column in %s: %w", sheetName, err)
+			}
+		}
+
+		for row, hidden := range sheet.HiddenRows {
+			if !hidden {
+				continue
+			}
+			if err := f.SetRowVisible(sheetName, row+1, false); err != nil {
+				return fmt.Errorf("failed to hide row in %s: %w", sheetName, err)
 			}
 		}
+
+		for col, level := range sheet.ColLevels {
+			name, err := excelize.ColumnNumberToName(col + 1)
+			if err != nil || level <= 0 {
+				continue
+			}
+			if err := f.SetColOutlineLevel(sheetName, name, uint8(level)); err != nil {
+				return fmt.Errorf("failed to group columns in %s: %w", sheetName, err)
+			}
+		}
+
+		for row, level := range sheet.RowLevels {
+			if level <= 0 {
+				continue
+			}
+			if err := f.SetRowOutlineLevel(sheetName, row+1, uint8(level)); err != nil {
+				return fmt.Errorf("failed to group rows in %s: %w", sheetName, err)
+			}
+		}
+
+		for _, rule := range sheet.Validations {
+			dv, err := validationOf(rule)
+			if err == nil {
+				err = f.AddDataValidation(sheetName, dv)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to add data validation to %s: %w", sheetName, err)
+			}
+		}
+
+		if err := setFreeze(f, sheetName, sheet.FreezeRows, sheet.FreezeCols); err != nil {
+			return fmt.Errorf("failed to freeze panes of %s: %w", sheetName, err)
+		}
 	}
 
 	if err := f.SaveAs(filename); err != nil {
@@ -60,6 +145,67 @@ func SaveExcel(sheets []models.Sheet, filename string) error {
 	return nil
 }
 
+// commentOf builds the comment of a cell the way Excel writes one, starting
+// with its author's name in bold
+func commentOf(cellRef string, cell models.Cell) excelize.Comment {
+	author := cell.CommentAuthor
+	if author == "" {
+		author = "vex"
+	}
+	return excelize.Comment{
+		Cell:   cellRef,
+		Author: author,
+		Paragraph: []excelize.RichTextRun{
+			{Text: author + ":", Font: &excelize.Font{Bold: true}},
+			{Text: "\n" + cell.Comment},
+		},
+	}
+}
+
+// setLink writes a cell's hyperlink, a place in the workbook when it starts
+// with # and a URL otherwise, and styles the cell the way Excel shows links
+func setLink(f *excelize.File, sheetName, cellRef, link string) error {
+	linkType := "External"
+	if location, ok := strings.CutPrefix(link, "#"); ok {
+		link, linkType = location, "Location"
+	}
+	if err := f.SetCellHyperLink(sheetName, cellRef, link, linkType); err != nil {
+		return err
+	}
+	style, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "0563C1", Underline: "single"}})
+	if err != nil {
+		return err
+	}
+	return f.SetCellStyle(sheetName, cellRef, cellRef, style)
+}
+
+// setFreeze writes the frozen rows and columns of a sheet into its view
+func setFreeze(f *excelize.File, sheetName string, rows, cols int) error {
+	if rows <= 0 && cols <= 0 {
+		return nil
+	}
+	topLeft, err := excelize.CoordinatesToCellName(cols+1, rows+1)
+	if err != nil {
+		return err
+	}
+
+	// The active pane is the one that scrolls in both directions
+	pane := "bottomRight"
+	if cols == 0 {
+		pane = "bottomLeft"
+	} else if rows == 0 {
+		pane = "topRight"
+	}
+	return f.SetPanes(sheetName, &excelize.Panes{
+		Freeze:      true,
+		XSplit:      cols,
+		YSplit:      rows,
+		TopLeftCell: topLeft,
+		ActivePane:  pane,
+		Selection:   []excelize.Selection{{SQRef: topLeft, ActiveCell: topLeft, Pane: pane}},
+	})
+}
+
 // SaveCSV saves a sheet to CSV format
 func SaveCSV(sheet models.Sheet, filename string) error {
 	file, err := os.Create(filename)
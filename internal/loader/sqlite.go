@@ -0,0 +1,507 @@
+package loader
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	_ "modernc.org/sqlite" // pure Go SQLite driver
+)
+
+// sqlitePageSize is the number of rows fetched per query when paging a table
+const sqlitePageSize = 500
+
+// sqliteTable pages the rows of one table or view of an open database into a
+// sheet and writes cell edits back as UPDATE statements
+type sqliteTable struct {
+	db       *sql.DB
+	path     string
+	name     string
+	columns  []string
+	hasRowID bool
+	total    int
+	loaded   int
+	rowIDs   []int64
+	original [][]string
+}
+
+// IsSQLiteFile reports whether filename has a SQLite database extension
+func IsSQLiteFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return true
+	}
+	return false
+}
+
+// loadSQLite opens a SQLite database and returns one sheet per table or view.
+// Only the first page of rows is loaded; the rest is fetched through the
+// sheet's RowSource. Views and tables without a rowid are read-only.
+func loadSQLite(filename string) ([]models.Sheet, error) {
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	rows, err := db.Query(`SELECT name, type FROM sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY rowid`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read SQLite schema: %w", err)
+	}
+
+	type entry struct{ name, kind string }
+	var entries []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.name, &e.kind); err != nil {
+			rows.Close()
+			db.Close()
+			return nil, fmt.Errorf("failed to read SQLite schema: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+
+	if len(entries) == 0 {
+		db.Close()
+		return nil, fmt.Errorf("no tables found in SQLite database")
+	}
+
+	sheets := make([]models.Sheet, 0, len(entries))
+	for _, e := range entries {
+		table, err := openSQLiteTable(db, filename, e.name, e.kind == "table")
+		if err != nil {
+			// Skip tables that can't be read
+			continue
+		}
+
+		header := make([]models.Cell, len(table.columns))
+		for j, name := range table.columns {
+			header[j] = models.Cell{Value: name, Row: 0, Col: j}
+		}
+
+		sheet := models.Sheet{
+			Name:     e.name,
+			Rows:     [][]models.Cell{header},
+			MaxRows:  table.total + 1,
+			MaxCols:  len(table.columns),
+			ReadOnly: !table.hasRowID,
+			Source:   table,
+		}
+		if err := table.LoadRows(&sheet, sqlitePageSize); err != nil {
+			continue
+		}
+		sheets = append(sheets, sheet)
+	}
+
+	if len(sheets) == 0 {
+		db.Close()
+	}
+	return sheets, nil
+}
+
+// openSQLiteTable reads the column names and row count of a table or view
+func openSQLiteTable(db *sql.DB, path, name string, isTable bool) (*sqliteTable, error) {
+	quoted := QuoteSQLIdentifier(name)
+
+	rows, err := db.Query("SELECT * FROM " + quoted + " LIMIT 0")
+	if err != nil {
+		return nil, err
+	}
+	columns, err := rows.Columns()
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	table := &sqliteTable{db: db, path: path, name: name, columns: columns}
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + quoted).Scan(&table.total); err != nil {
+		return nil, err
+	}
+
+	if isTable {
+		// WITHOUT ROWID tables reject this query and stay read-only
+		if rows, err := db.Query("SELECT rowid FROM " + quoted + " LIMIT 0"); err == nil {
+			rows.Close()
+			table.hasRowID = true
+		}
+	}
+
+	return table, nil
+}
+
+// Complete reports whether every row of the table has been loaded
+func (t *sqliteTable) Complete() bool {
+	return t.loaded >= t.total
+}
+
+// Close closes the database connection. The tables of a database share it,
+// and closing it again is harmless.
+func (t *sqliteTable) Close() error {
+	return t.db.Close()
+}
+
+// LoadRows fetches pages of rows with LIMIT/OFFSET until the sheet holds at
+// least upto rows (including the header row) or the table is exhausted
+func (t *sqliteTable) LoadRows(sheet *models.Sheet, upto int) error {
+	for len(sheet.Rows) < upto && !t.Complete() {
+		if err := t.loadPage(sheet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPage appends the next page of rows to the sheet
+func (t *sqliteTable) loadPage(sheet *models.Sheet) error {
+	quoted := QuoteSQLIdentifier(t.name)
+	query := "SELECT * FROM " + quoted + " LIMIT ? OFFSET ?"
+	if t.hasRowID {
+		query = "SELECT rowid, * FROM " + quoted + " ORDER BY rowid LIMIT ? OFFSET ?"
+	}
+
+	rows, err := t.db.Query(query, sqlitePageSize, t.loaded)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", t.name, err)
+	}
+	defer rows.Close()
+
+	width := len(t.columns)
+	if t.hasRowID {
+		width++
+	}
+	raw := make([]any, width)
+	dest := make([]any, width)
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+
+	fetched := 0
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("failed to read %s: %w", t.name, err)
+		}
+
+		values := raw
+		if t.hasRowID {
+			id, _ := raw[0].(int64)
+			t.rowIDs = append(t.rowIDs, id)
+			values = raw[1:]
+		}
+
+		rowIdx := len(sheet.Rows)
+		cells := make([]models.Cell, len(t.columns))
+		original := make([]string, len(t.columns))
+		for j, v := range values {
+			original[j] = sqliteValueString(v)
+			cells[j] = models.Cell{Value: original[j], Row: rowIdx, Col: j}
+		}
+		sheet.Rows = append(sheet.Rows, cells)
+		t.original = append(t.original, original)
+		fetched++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", t.name, err)
+	}
+
+	t.loaded += fetched
+	if fetched == 0 {
+		// The table shrank underneath us; stop paging
+		t.total = t.loaded
+		sheet.MaxRows = len(sheet.Rows)
+	}
+	return nil
+}
+
+// sqliteValueString renders a scanned SQLite value as cell text
+func sqliteValueString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return fmt.Sprintf("x'%X'", v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// sqliteChange is a pending UPDATE for one row
+type sqliteChange struct {
+	table *sqliteTable
+	index int
+	row   []string
+}
+
+// SaveSQLite writes the sheets to a SQLite database. Sheets loaded from the
+// same database file have their edited cells written back as UPDATE statements
+// in a single transaction; empty cells become NULL. For any other target the
+// sheets are exported to a new database.
+func SaveSQLite(sheets []models.Sheet, filename string) error {
+	var db *sql.DB
+	var changes []sqliteChange
+
+	for _, sheet := range sheets {
+		table, ok := sheet.Source.(*sqliteTable)
+		if !ok || !sameFile(table.path, filename) {
+			continue
+		}
+		db = table.db
+		if sheet.ReadOnly {
+			continue
+		}
+
+		if len(sheet.Rows) > 0 {
+			for j, name := range table.columns {
+				if j >= len(sheet.Rows[0]) || sheet.Rows[0][j].Value != name {
+					return fmt.Errorf("%s: column names cannot be changed", table.name)
+				}
+			}
+		}
+
+		for i := 0; i < table.loaded && i+1 < len(sheet.Rows); i++ {
+			row := make([]string, len(table.columns))
+			for j := range row {
+				if j < len(sheet.Rows[i+1]) {
+					row[j] = sheet.Rows[i+1][j].Value
+				}
+			}
+			for j := range row {
+				if row[j] != table.original[i][j] {
+					changes = append(changes, sqliteChange{table: table, index: i, row: row})
+					break
+				}
+			}
+		}
+	}
+
+	if db == nil {
+		for i := range sheets {
+			if err := LoadAllRows(&sheets[i]); err != nil {
+				return err
+			}
+		}
+		return ExportSQLite(sheets, filename)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	for _, c := range changes {
+		var sets []string
+		var args []any
+		for j, value := range c.row {
+			if value == c.table.original[c.index][j] {
+				continue
+			}
+			sets = append(sets, QuoteSQLIdentifier(c.table.columns[j])+" = ?")
+			args = append(args, sqliteArg(value))
+		}
+		args = append(args, c.table.rowIDs[c.index])
+
+		query := "UPDATE " + QuoteSQLIdentifier(c.table.name) + " SET " + strings.Join(sets, ", ") + " WHERE rowid = ?"
+		if _, err := tx.Exec(query, args...); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to update %s: %w", c.table.name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	for _, c := range changes {
+		c.table.original[c.index] = c.row
+	}
+	return nil
+}
+
+// sqliteArg converts cell text to a statement argument, mapping empty text to NULL
+func sqliteArg(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// ExportSQLite writes the sheets into a new SQLite database, one table per
+// sheet. The first row of each sheet supplies the column names and column
+// types are inferred from the data. An existing file is replaced only once
+// the new database has been written in full, so a failed export leaves it
+// untouched.
+func ExportSQLite(sheets []models.Sheet, filename string) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create SQLite database: %w", err)
+	}
+	tmpName := tmp.Name()
+	tmp.Close()
+	// Once renamed into place there is nothing left to remove
+	defer os.Remove(tmpName)
+
+	db, err := sql.Open("sqlite", tmpName)
+	if err != nil {
+		return fmt.Errorf("failed to create SQLite database: %w", err)
+	}
+	err = writeSQLiteTables(db, sheets, sqliteTableNames(sheets, SQLIdentifier))
+	if closeErr := db.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close SQLite database: %w", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmpName, mode); err != nil {
+		return fmt.Errorf("failed to create SQLite database: %w", err)
+	}
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", filename, err)
+	}
+	return nil
+}
+
+// sqliteTableNames derives a unique table name for each sheet using name.
+// Sheets without data get an empty name and are skipped on export.
+func sqliteTableNames(sheets []models.Sheet, name func(string) string) []string {
+	tables := make([]string, len(sheets))
+	used := make(map[string]bool)
+	for i, sheet := range sheets {
+		if len(sheet.Rows) == 0 || sheetWidth(sheet) == 0 {
+			continue
+		}
+
+		base := name(sheet.Name)
+		table := base
+		for n := 2; used[strings.ToLower(table)]; n++ {
+			table = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[strings.ToLower(table)] = true
+		tables[i] = table
+	}
+	return tables
+}
+
+// writeSQLiteTables creates a table for each sheet with a non-empty name in
+// tables, inside a single transaction
+func writeSQLiteTables(db *sql.DB, sheets []models.Sheet, tables []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	for i, sheet := range sheets {
+		if tables[i] == "" {
+			continue
+		}
+		if err := exportSQLiteTable(tx, tables[i], sheet); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("sheet %s: %w", sheet.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit export: %w", err)
+	}
+	return nil
+}
+
+// exportSQLiteTable creates a table for the sheet and inserts its data rows
+func exportSQLiteTable(tx *sql.Tx, table string, sheet models.Sheet) error {
+	cols := sheetWidth(sheet)
+	names := SQLColumnNames(sheet)
+	types := InferColumnTypes(sheet)
+
+	defs := make([]string, cols)
+	quoted := make([]string, cols)
+	marks := make([]string, cols)
+	for j := range defs {
+		quoted[j] = QuoteSQLIdentifier(names[j])
+		defs[j] = quoted[j] + " " + types[j].SQL()
+		marks[j] = "?"
+	}
+
+	if _, err := tx.Exec("CREATE TABLE " + QuoteSQLIdentifier(table) + " (" + strings.Join(defs, ", ") + ")"); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO " + QuoteSQLIdentifier(table) +
+		" (" + strings.Join(quoted, ", ") + ") VALUES (" + strings.Join(marks, ", ") + ")")
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	args := make([]any, cols)
+	for _, row := range sheet.Rows[1:] {
+		for j, value := range rowValues(row, cols) {
+			args[j] = typedSQLiteArg(value, types[j])
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			return fmt.Errorf("failed to insert row: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// typedSQLiteArg converts cell text to a value matching the inferred column type
+func typedSQLiteArg(value string, t ColumnType) any {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil
+	}
+	switch t {
+	case ColumnInteger:
+		if n, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return n
+		}
+	case ColumnReal:
+		if f, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
+
+// sameFile reports whether two paths refer to the same file
+func sameFile(a, b string) bool {
+	if a == b {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
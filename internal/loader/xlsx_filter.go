@@ -0,0 +1,251 @@
+package loader
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxAutoFilter is the autoFilter element of a worksheet
+type xlsxAutoFilter struct {
+	Ref     string `xml:"ref,attr"`
+	Columns []struct {
+		ColID   int `xml:"colId,attr"`
+		Filters *struct {
+			Blank  bool `xml:"blank,attr"`
+			Values []struct {
+				Val string `xml:"val,attr"`
+			} `xml:"filter"`
+		} `xml:"filters"`
+		Custom *struct {
+			And     bool `xml:"and,attr"`
+			Filters []struct {
+				Operator string `xml:"operator,attr"`
+				Val      string `xml:"val,attr"`
+			} `xml:"customFilter"`
+		} `xml:"customFilters"`
+	} `xml:"filterColumn"`
+}
+
+// readAutoFilters reads the autofilter of each worksheet in an xlsx file,
+// keyed by sheet name. excelize can write autofilters but not read them, so
+// the worksheet XML is read directly. Criteria vex cannot express (top 10,
+// dynamic and color filters) are skipped.
+func readAutoFilters(filename string) (map[string]xlsxAutoFilter, error) {
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	parts := make(map[string]*zip.File, len(zr.File))
+	for _, file := range zr.File {
+		parts[file.Name] = file
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodePart(parts["xl/workbook.xml"], &workbook); err != nil {
+		return nil, err
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(parts["xl/_rels/workbook.xml.rels"], &rels); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join("xl", rel.Target)
+		}
+	}
+
+	filters := make(map[string]xlsxAutoFilter)
+	for _, sheet := range workbook.Sheets {
+		part, ok := parts[targets[sheet.RID]]
+		if !ok {
+			continue
+		}
+		filter, found, err := findAutoFilter(part)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			filters[sheet.Name] = filter
+		}
+	}
+	return filters, nil
+}
+
+// decodePart unmarshals an XML part of the package
+func decodePart(file *zip.File, v any) error {
+	if file == nil {
+		return fmt.Errorf("missing package part")
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// findAutoFilter streams a worksheet part looking for its autoFilter element
+func findAutoFilter(file *zip.File) (xlsxAutoFilter, bool, error) {
+	var filter xlsxAutoFilter
+
+	rc, err := file.Open()
+	if err != nil {
+		return filter, false, err
+	}
+	defer rc.Close()
+
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return filter, false, nil
+		}
+		if err != nil {
+			return filter, false, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "sheetData":
+			if err := dec.Skip(); err != nil {
+				return filter, false, err
+			}
+		case "autoFilter":
+			err := dec.DecodeElement(&filter, &start)
+			return filter, err == nil, err
+		}
+	}
+}
+
+// sheetFilters converts an autofilter into sheet filters and the index of its
+// header row
+func sheetFilters(af xlsxAutoFilter) ([]models.Filter, int) {
+	startRef, _, _ := strings.Cut(af.Ref, ":")
+	startCol, startRow, err := excelize.CellNameToCoordinates(startRef)
+	if err != nil {
+		return nil, 0
+	}
+
+	var filters []models.Filter
+	for _, column := range af.Columns {
+		col := startCol - 1 + column.ColID
+
+		if column.Filters != nil {
+			values := make([]string, 0, len(column.Filters.Values)+1)
+			for _, v := range column.Filters.Values {
+				values = append(values, v.Val)
+			}
+			if column.Filters.Blank {
+				values = append(values, "")
+			}
+			filters = append(filters, models.Filter{Col: col, Op: models.FilterValues, Values: values})
+		}
+
+		if column.Custom == nil {
+			continue
+		}
+		var custom []models.Filter
+		for _, cf := range column.Custom.Filters {
+			if f, ok := customFilter(col, cf.Operator, cf.Val); ok {
+				custom = append(custom, f)
+			}
+		}
+		if column.Custom.And || len(column.Custom.Filters) == 1 {
+			filters = append(filters, custom...)
+			continue
+		}
+
+		// Either-or criteria can only be kept when they are plain values
+		values := make([]string, 0, len(custom))
+		for _, f := range custom {
+			if f.Op != models.FilterEquals {
+				values = nil
+				break
+			}
+			values = append(values, f.Value)
+		}
+		if len(values) > 0 {
+			filters = append(filters, models.Filter{Col: col, Op: models.FilterValues, Values: values})
+		}
+	}
+
+	return filters, startRow - 1
+}
+
+// customFilter converts one customFilter element. Equality criteria may use
+// the * and ? wildcards.
+func customFilter(col int, operator, val string) (models.Filter, bool) {
+	ops := map[string]models.FilterOp{
+		"":                   models.FilterEquals,
+		"equal":              models.FilterEquals,
+		"notEqual":           models.FilterNotEquals,
+		"greaterThan":        models.FilterGreater,
+		"greaterThanOrEqual": models.FilterGreaterEq,
+		"lessThan":           models.FilterLess,
+		"lessThanOrEqual":    models.FilterLessEq,
+	}
+	op, ok := ops[operator]
+	if !ok {
+		return models.Filter{}, false
+	}
+
+	if (op == models.FilterEquals || op == models.FilterNotEquals) && strings.ContainsAny(val, "*?") {
+		inner := strings.TrimSuffix(strings.TrimPrefix(val, "*"), "*")
+		switch {
+		case op == models.FilterNotEquals:
+			return models.Filter{}, false
+		case len(val) > 2 && val[0] == '*' && val[len(val)-1] == '*' && !strings.ContainsAny(inner, "*?"):
+			return models.Filter{Col: col, Op: models.FilterContains, Value: inner}, true
+		default:
+			return models.Filter{Col: col, Op: models.FilterRegex, Value: wildcardRegex(val)}, true
+		}
+	}
+
+	return models.Filter{Col: col, Op: op, Value: val}, true
+}
+
+// wildcardRegex converts an Excel wildcard pattern into an anchored,
+// case-insensitive regular expression
+func wildcardRegex(pattern string) string {
+	var b strings.Builder
+	b.WriteString("(?i)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package loader
+
+import (
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// ParseLocation splits a place in the workbook, such as Sheet2!B4,
+// 'Q1 Sales'!A1:C3 or just A1, into its sheet name and first cell. The sheet
+// is empty when the location is on the same sheet.
+func ParseLocation(location string) (sheet, cell string, ok bool) {
+	sheet, r, ok := ParseRange(location)
+	if !ok {
+		return "", "", false
+	}
+	cell, err := excelize.CoordinatesToCellName(r.StartCol+1, r.StartRow+1)
+	if err != nil {
+		return "", "", false
+	}
+	return sheet, cell, true
+}
+
+// ParseRange splits a range of the workbook, such as Sheet2!$A$1:$A$9 or
+// B2, into its sheet name, empty for the same sheet, and cells
+func ParseRange(location string) (sheet string, r models.CellRange, ok bool) {
+	location = strings.TrimPrefix(strings.TrimSpace(location), "#")
+	ref := location
+	if i := strings.LastIndex(location, "!"); i >= 0 {
+		sheet, ref = location[:i], location[i+1:]
+		if len(sheet) >= 2 && strings.HasPrefix(sheet, "'") && strings.HasSuffix(sheet, "'") {
+			sheet = strings.ReplaceAll(sheet[1:len(sheet)-1], "''", "'")
+		}
+		if sheet == "" {
+			return "", r, false
+		}
+	}
+
+	start, end, found := strings.Cut(strings.ReplaceAll(ref, "$", ""), ":")
+	if !found {
+		end = start
+	}
+	startCol, startRow, err := excelize.CellNameToCoordinates(start)
+	if err != nil {
+		return "", r, false
+	}
+	endCol, endRow, err := excelize.CellNameToCoordinates(end)
+	if err != nil {
+		return "", r, false
+	}
+	return sheet, models.CellRange{
+		StartRow: min(startRow, endRow) - 1,
+		StartCol: min(startCol, endCol) - 1,
+		EndRow:   max(startRow, endRow) - 1,
+		EndCol:   max(startCol, endCol) - 1,
+	}, true
+}
+
+// linkOf turns the target excelize reports for a hyperlink into a cell link.
+// excelize returns URLs and places in the workbook alike, so anything that
+// reads as a location and has no URL scheme is taken to be one.
+func linkOf(target string) string {
+	if strings.Contains(target, "://") || strings.HasPrefix(strings.ToLower(target), "mailto:") {
+		return target
+	}
+	if _, _, ok := ParseLocation(target); ok {
+		return "#" + strings.TrimPrefix(target, "#")
+	}
+	return target
+}
@@ -0,0 +1,204 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/CodeOne45/vex-tui/pkg/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// validationTypes are the xlsx names of the validation types
+var validationTypes = map[models.ValidationType]string{
+	models.ValidateList:       "list",
+	models.ValidateWhole:      "whole",
+	models.ValidateDecimal:    "decimal",
+	models.ValidateDate:       "date",
+	models.ValidateTime:       "time",
+	models.ValidateTextLength: "textLength",
+	models.ValidateCustom:     "custom",
+}
+
+// validationOps are the xlsx names of the validation operators
+var validationOps = map[models.ValidationOp]string{
+	models.ValidateBetween:    "between",
+	models.ValidateNotBetween: "notBetween",
+	models.ValidateEqual:      "equal",
+	models.ValidateNotEqual:   "notEqual",
+	models.ValidateGreater:    "greaterThan",
+	models.ValidateGreaterEq:  "greaterThanOrEqual",
+	models.ValidateLess:       "lessThan",
+	models.ValidateLessEq:     "lessThanOrEqual",
+}
+
+// sheetValidations converts the data validations of a sheet. Rules that
+// allow any value are left out, and a list drawn from a defined name takes
+// the range the name refers to.
+func sheetValidations(dvs []*excelize.DataValidation, names []excelize.DefinedName, sheetName string) []models.Validation {
+	var rules []models.Validation
+	for _, dv := range dvs {
+		typ, ok := validationType(dv.Type)
+		if !ok {
+			continue
+		}
+		var ranges []models.CellRange
+		for _, ref := range strings.Fields(dv.Sqref) {
+			if _, r, ok := ParseRange(ref); ok {
+				ranges = append(ranges, r)
+			}
+		}
+		if len(ranges) == 0 {
+			continue
+		}
+
+		rule := models.Validation{
+			Ranges:     ranges,
+			Type:       typ,
+			AllowBlank: dv.AllowBlank,
+			NoDropDown: dv.ShowDropDown,
+		}
+		for op, name := range validationOps {
+			if name == dv.Operator {
+				rule.Op = op
+			}
+		}
+
+		formula1, formula2 := validationFormulas(dv.Formula1 + dv.Formula2)
+		switch {
+		case typ != models.ValidateList:
+			rule.Min, rule.Max = formula1, formula2
+		case strings.HasPrefix(formula1, `"`):
+			for _, value := range strings.Split(strings.Trim(formula1, `"`), ",") {
+				rule.Values = append(rule.Values, strings.TrimSpace(strings.ReplaceAll(value, `""`, `"`)))
+			}
+		default:
+			rule.Source = definedRange(formula1, names, sheetName)
+		}
+
+		if dv.ShowErrorMessage {
+			// Excel stops invalid values unless told otherwise
+			rule.ErrorStyle = "stop"
+			if dv.ErrorStyle != nil && *dv.ErrorStyle != "" {
+				rule.ErrorStyle = *dv.ErrorStyle
+			}
+			if dv.ErrorTitle != nil {
+				rule.ErrorTitle = *dv.ErrorTitle
+			}
+			if dv.Error != nil {
+				rule.Error = *dv.Error
+			}
+		}
+		if dv.ShowInputMessage {
+			if dv.PromptTitle != nil {
+				rule.PromptTitle = *dv.PromptTitle
+			}
+			if dv.Prompt != nil {
+				rule.Prompt = *dv.Prompt
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// validationType looks up a validation type by its xlsx name
+func validationType(name string) (models.ValidationType, bool) {
+	for typ, typeName := range validationTypes {
+		if typeName == name {
+			return typ, true
+		}
+	}
+	return 0, false
+}
+
+// validationFormulas reads the formula1 and formula2 elements excelize
+// hands over as raw XML
+func validationFormulas(inner string) (string, string) {
+	var formulas struct {
+		Formula1 string `xml:"formula1"`
+		Formula2 string `xml:"formula2"`
+	}
+	if err := xml.Unmarshal([]byte("<v>"+inner+"</v>"), &formulas); err != nil {
+		return "", ""
+	}
+	return strings.TrimSpace(formulas.Formula1), strings.TrimSpace(formulas.Formula2)
+}
+
+// definedRange returns the range a defined name visible from a sheet refers
+// to, or ref itself when it is not such a name
+func definedRange(ref string, names []excelize.DefinedName, sheetName string) string {
+	for _, name := range names {
+		if strings.EqualFold(name.Name, ref) && (name.Scope == "" || name.Scope == "Workbook" || name.Scope == sheetName) {
+			return strings.TrimPrefix(name.RefersTo, "=")
+		}
+	}
+	return ref
+}
+
+// validationOf builds the xlsx data validation of a rule
+func validationOf(rule models.Validation) (*excelize.DataValidation, error) {
+	dv := excelize.NewDataValidation(rule.AllowBlank)
+	refs := make([]string, 0, len(rule.Ranges))
+	for _, r := range rule.Ranges {
+		refs = append(refs, rangeRef(r))
+	}
+	dv.Sqref = strings.Join(refs, " ")
+	dv.ShowDropDown = rule.NoDropDown
+
+	switch {
+	case rule.Type == models.ValidateList && rule.Source == "":
+		if err := dv.SetDropList(rule.Values); err != nil {
+			return nil, err
+		}
+	case rule.Type == models.ValidateList:
+		dv.Type = validationTypes[rule.Type]
+		dv.Formula1 = formulaElement("formula1", rule.Source)
+	default:
+		dv.Type = validationTypes[rule.Type]
+		dv.Formula1 = formulaElement("formula1", rule.Min)
+		if rule.Type != models.ValidateCustom {
+			dv.Operator = validationOps[rule.Op]
+			if rule.Op == models.ValidateBetween || rule.Op == models.ValidateNotBetween {
+				dv.Formula2 = formulaElement("formula2", rule.Max)
+			}
+		}
+	}
+
+	if rule.ErrorStyle != "" {
+		style, title, message := rule.ErrorStyle, rule.ErrorTitle, rule.Error
+		dv.ShowErrorMessage = true
+		dv.ErrorStyle = &style
+		if title != "" {
+			dv.ErrorTitle = &title
+		}
+		if message != "" {
+			dv.Error = &message
+		}
+	}
+	if rule.PromptTitle != "" || rule.Prompt != "" {
+		title, prompt := rule.PromptTitle, rule.Prompt
+		dv.ShowInputMessage = true
+		dv.PromptTitle, dv.Prompt = &title, &prompt
+	}
+	return dv, nil
+}
+
+// formulaElement writes a formula as the raw XML element excelize expects
+func formulaElement(name, formula string) string {
+	var b bytes.Buffer
+	b.WriteString("<" + name + ">")
+	_ = xml.EscapeText(&b, []byte(formula))
+	b.WriteString("</" + name + ">")
+	return b.String()
+}
+
+// rangeRef writes a range as an A1 reference, a single cell as just its name
+func rangeRef(r models.CellRange) string {
+	start, _ := excelize.CoordinatesToCellName(r.StartCol+1, r.StartRow+1)
+	if r.StartRow == r.EndRow && r.StartCol == r.EndCol {
+		return start
+	}
+	end, _ := excelize.CoordinatesToCellName(r.EndCol+1, r.EndRow+1)
+	return start + ":" + end
+}
@@ -1,12 +1,14 @@
 package ui
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/CodeOne45/vex-tui/internal/theme"
 	"github.com/CodeOne45/vex-tui/pkg/models"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 const (
@@ -164,6 +166,75 @@ func ColIndexToLetter(index int) string {
 	return result
 }
 
+// ParseCellRef parses an A1-style reference into 0-indexed row and column.
+// Absolute markers ($A$1) are accepted and ignored.
+func ParseCellRef(ref string) (row, col int, err error) {
+	ref = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(ref), "$", ""))
+
+	i := 0
+	for i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z' {
+		col = col*26 + int(ref[i]-'A') + 1
+		i++
+	}
+	if i == 0 || i == len(ref) {
+		return 0, 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+
+	row, err = strconv.Atoi(ref[i:])
+	if err != nil || row < 1 {
+		return 0, 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+
+	return row - 1, col - 1, nil
+}
+
+// ParseColumn parses column letters such as "B" or "AA" into a 0-indexed
+// column number
+func ParseColumn(s string) (int, error) {
+	letters := strings.ToUpper(strings.TrimSpace(s))
+	if letters == "" {
+		return 0, fmt.Errorf("invalid column %q", s)
+	}
+
+	col := 0
+	for i := 0; i < len(letters); i++ {
+		if letters[i] < 'A' || letters[i] > 'Z' {
+			return 0, fmt.Errorf("invalid column %q", s)
+		}
+		col = col*26 + int(letters[i]-'A') + 1
+	}
+	return col - 1, nil
+}
+
+// ParseRange parses an A1:D20 style range (or a single cell) into normalized
+// 0-indexed bounds
+func ParseRange(s string) (startRow, startCol, endRow, endCol int, err error) {
+	parts := strings.Split(s, ":")
+	if len(parts) > 2 {
+		return 0, 0, 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+
+	startRow, startCol, err = ParseCellRef(parts[0])
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	endRow, endCol = startRow, startCol
+	if len(parts) == 2 {
+		endRow, endCol, err = ParseCellRef(parts[1])
+		if err != nil {
+			return 0, 0, 0, 0, err
+		}
+	}
+
+	if startRow > endRow {
+		startRow, endRow = endRow, startRow
+	}
+	if startCol > endCol {
+		startCol, endCol = endCol, startCol
+	}
+	return startRow, startCol, endRow, endCol, nil
+}
+
 // Truncate truncates a string to maxLen with ellipsis
 func Truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -175,35 +246,44 @@ func Truncate(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-// TruncateToWidth ensures text fits exactly in the cell width
+// TruncateToWidth ensures text fits exactly in the cell width, measured in
+// terminal columns so that wide characters take the room they are drawn in
 func TruncateToWidth(s string, width int) string {
-	// Remove any newlines or tabs that would break layout
-	s = strings.ReplaceAll(s, "\n", " ")
-	s = strings.ReplaceAll(s, "\t", " ")
-	s = strings.ReplaceAll(s, "\r", " ")
+	s = flatten(s)
 
-	runes := []rune(s)
-
-	if len(runes) > width {
+	if w := runewidth.StringWidth(s); w > width {
 		if width <= 3 {
-			return strings.Repeat(".", width)
+			return runewidth.FillRight(runewidth.Truncate(s, width, ""), width)
 		}
-		return string(runes[:width-3]) + "..."
-	} else if len(runes) < width {
-		return s + strings.Repeat(" ", width-len(runes))
+		return runewidth.FillRight(runewidth.Truncate(s, width, "..."), width)
+	} else if w < width {
+		return s + strings.Repeat(" ", width-w)
 	}
 
 	return s
 }
 
+// DisplayWidth returns the number of terminal columns text takes in a cell
+func DisplayWidth(s string) int {
+	return runewidth.StringWidth(flatten(s))
+}
+
+// flatten replaces newlines and tabs that would break the layout of a cell
+func flatten(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "\t", " ")
+	return strings.ReplaceAll(s, "\r", " ")
+}
+
 // PadCenter centers text in a field of given width
 func PadCenter(s string, width int) string {
-	if len(s) >= width {
+	w := runewidth.StringWidth(s)
+	if w >= width {
 		return TruncateToWidth(s, width)
 	}
 
-	leftPad := (width - len(s)) / 2
-	rightPad := width - len(s) - leftPad
+	leftPad := (width - w) / 2
+	rightPad := width - w - leftPad
 
 	return strings.Repeat(" ", leftPad) + s + strings.Repeat(" ", rightPad)
 }
@@ -235,6 +315,43 @@ func WrapText(text string, width int) string {
 	return result.String()
 }
 
+// WrapLines word-wraps text into lines of at most width terminal columns,
+// keeping its own line breaks and breaking words longer than a line
+func WrapLines(text string, width int) []string {
+	width = Max(1, width)
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\t", " ")
+
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line, lineWidth := "", 0
+		for _, word := range strings.Fields(para) {
+			wordWidth := runewidth.StringWidth(word)
+			if lineWidth > 0 && lineWidth+1+wordWidth <= width {
+				line += " " + word
+				lineWidth += 1 + wordWidth
+				continue
+			}
+			if lineWidth > 0 {
+				lines = append(lines, line)
+				line, lineWidth = "", 0
+			}
+			for wordWidth > width {
+				head := runewidth.Truncate(word, width, "")
+				if head == "" {
+					// A character wider than the line still gets a line
+					head = string([]rune(word)[:1])
+				}
+				lines = append(lines, head)
+				word = word[len(head):]
+				wordWidth = runewidth.StringWidth(word)
+			}
+			line, lineWidth = word, wordWidth
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
 // GetCellType determines the type of a cell
 func GetCellType(cell models.Cell) string {
 	if cell.Formula != "" {
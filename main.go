@@ -7,6 +7,7 @@ import (
 
 	"github.com/CodeOne45/vex-tui/internal/app"
 	"github.com/CodeOne45/vex-tui/internal/loader"
+	"github.com/CodeOne45/vex-tui/pkg/models"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -16,9 +17,17 @@ var (
 	showVersion = flag.Bool("version", false, "Show version information")
 	showHelp    = flag.Bool("help", false, "Show help information")
 	themeName   = flag.String("theme", "catppuccin", "Set the color theme")
+	exportAll   = flag.String("export-all", "", "Export every sheet to a directory of CSV files or a single .json file, then exit")
+	opener      = flag.String("opener", "", "Command that opens links to URLs (default: $VEX_OPENER or the system opener)")
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
+
 	flag.StringVar(themeName, "t", "catppuccin", "Set the color theme (shorthand)")
 	flag.Parse()
 
@@ -33,43 +42,85 @@ func main() {
 	}
 
 	args := flag.Args()
-	if len(args) < 1 {
-		printUsage()
-		os.Exit(1)
+	if len(args) == 0 {
+		if !stdinIsPipe() {
+			printUsage()
+			os.Exit(1)
+		}
+		args = []string{"-"}
 	}
 
-	filename := args[0]
-
-	// Validate file exists
-	if err := validateFile(filename); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if *exportAll != "" && len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "Error: --export-all takes a single file")
 		os.Exit(1)
 	}
 
-	// Load file
-	sheets, err := loader.LoadFile(filename)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading file: %v\n", err)
-		os.Exit(1)
+	// Load every file; each one becomes a buffer in the session
+	filenames := make([]string, len(args))
+	workbooks := make([][]models.Sheet, len(args))
+	readStdin := false
+	for i, arg := range args {
+		if arg == "-" && readStdin {
+			fmt.Fprintln(os.Stderr, "Error: stdin can only be read once")
+			os.Exit(1)
+		}
+		filename, sheets, err := loadInput(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		readStdin = readStdin || arg == "-"
+		filenames[i] = filename
+		workbooks[i] = sheets
 	}
 
-	if len(sheets) == 0 {
-		fmt.Fprintln(os.Stderr, "Error: No sheets found in file")
-		os.Exit(1)
+	if *exportAll != "" {
+		sheets := workbooks[0]
+		for i := range sheets {
+			if err := loader.LoadAllRows(&sheets[i]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error loading file: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		written, err := loader.ExportWorkbook(sheets, *exportAll)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting: %v\n", err)
+			os.Exit(1)
+		}
+		for _, path := range written {
+			fmt.Println(path)
+		}
+		os.Exit(0)
 	}
 
 	// Create and run application
-	model := app.NewModel(filename, sheets, *themeName)
-	program := tea.NewProgram(
-		model,
+	model := app.NewModel(filenames[0], workbooks[0], *themeName)
+	for i := 1; i < len(filenames); i++ {
+		model.AddBuffer(filenames[i], workbooks[i])
+	}
+	if *opener != "" {
+		model.SetOpener(*opener)
+	}
+	opts := []tea.ProgramOption{
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
-	)
+	}
+	if readStdin {
+		// stdin was consumed by the data, so read keys from the terminal
+		opts = append(opts, tea.WithInputTTY())
+	}
+	program := tea.NewProgram(model, opts...)
 
-	if _, err := program.Run(); err != nil {
+	final, err := program.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)
 	}
+	if m, ok := final.(app.Model); ok {
+		if err := m.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to close file: %v\n", err)
+		}
+	}
 }
 
 func printVersion() {
@@ -81,11 +132,21 @@ func printVersion() {
 func printHelp() {
 	fmt.Printf("vex v%s - Terminal Excel Viewer\n\n", version)
 	fmt.Println("USAGE:")
-	fmt.Println("  vex [OPTIONS] <file>")
+	fmt.Println("  vex [OPTIONS] <file> [file...]")
+	fmt.Println("  <command> | vex [OPTIONS] [-]")
+	fmt.Println("  vex <command> [ARGS]")
 	fmt.Println("\nARGUMENTS:")
-	fmt.Println("  <file>    Path to Excel (.xlsx, .xlsm, .xls) or CSV file")
+	fmt.Println("  <file>    Path to Excel (.xlsx, .xlsm, .xls), CSV, TSV, JSON or SQLite (.db, .sqlite) file")
+	fmt.Println("            Use - or pipe data in to read CSV, TSV or JSON from stdin")
+	fmt.Println("\nCOMMANDS:")
+	fmt.Println("  cat <file> [--sheet S] [--range A1:D20] [--format F]   Print a sheet to stdout")
+	fmt.Println("  convert <input> <output> [--sheet S] [--all]          Convert between formats")
+	fmt.Println("  eval <file> <formula> [--sheet S]                     Evaluate a formula")
+	fmt.Println("  query <file> <sql> [--format F]                       Run SQL over the sheets")
 	fmt.Println("\nOPTIONS:")
 	fmt.Println("  -t, --theme <name>    Set color theme (default: catppuccin)")
+	fmt.Println("  --export-all <target> Export all sheets to <target>/ (CSV per sheet) or <target>.json")
+	fmt.Println("  --opener <command>    Open links to URLs with <command> (default: $VEX_OPENER or the system opener)")
 	fmt.Println("  --version             Show version information")
 	fmt.Println("  --help                Show this help message")
 	fmt.Println("\nAVAILABLE THEMES:")
@@ -95,10 +156,18 @@ func printHelp() {
 	fmt.Println("\nEXAMPLES:")
 	fmt.Println("  vex data.xlsx")
 	fmt.Println("  vex report.csv --theme nord")
+	fmt.Println("  vex jan.csv feb.csv mar.csv")
 	fmt.Println("  vex sales.xlsx -t tokyo-night")
+	fmt.Println("  vex --export-all out/ workbook.xlsx")
+	fmt.Println("  curl -s https://example.com/data.json | vex")
+	fmt.Println("  vex cat sales.xlsx --sheet Sales --range A1:D20")
+	fmt.Println("  vex convert sales.xlsx sales.csv")
+	fmt.Println("  vex eval sales.xlsx 'SUM(B2:B100)'")
+	fmt.Println("  vex query sales.xlsx 'SELECT region, SUM(amount) FROM Sales GROUP BY region'")
 	fmt.Println("\nKEYBOARD SHORTCUTS:")
 	fmt.Println("  Navigation:  ↑↓←→ / hjkl, PgUp/PgDn, Home/End")
 	fmt.Println("  Sheets:      Tab / Shift+Tab")
+	fmt.Println("  Files:       ] / [ (next/prev file), B (file list)")
 	fmt.Println("  Search:      / (search), n (next), N (prev)")
 	fmt.Println("  Actions:     Enter (details), Ctrl+G (jump), c (copy)")
 	fmt.Println("  Data viz:    V (select range), v (visualize)")
@@ -112,6 +181,46 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, "Try 'vex --help' for more information.\n")
 }
 
+// stdinIsPipe reports whether stdin is redirected from a pipe or file
+func stdinIsPipe() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// loadInput loads a file argument, or stdin when arg is -. It returns the
+// file name to save to, which is empty for stdin.
+func loadInput(arg string) (string, []models.Sheet, error) {
+	var (
+		filename string
+		sheets   []models.Sheet
+		err      error
+	)
+	if arg == "-" {
+		// The file name stays empty until Save As
+		sheets, err = loader.LoadReader(os.Stdin, "stdin")
+		if err != nil {
+			return "", nil, fmt.Errorf("reading stdin: %w", err)
+		}
+	} else {
+		filename = arg
+		if err := validateFile(filename); err != nil {
+			return "", nil, err
+		}
+		sheets, err = loader.LoadFile(filename)
+		if err != nil {
+			return "", nil, fmt.Errorf("loading %s: %w", filename, err)
+		}
+	}
+
+	if len(sheets) == 0 {
+		return "", nil, fmt.Errorf("no sheets found in %s", arg)
+	}
+	return filename, sheets, nil
+}
+
 func validateFile(filename string) error {
 	info, err := os.Stat(filename)
 	if os.IsNotExist(err) {
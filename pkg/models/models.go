@@ -4,8 +4,14 @@ package models
 type Cell struct {
 	Value   string
 	Formula string
-	Row     int
-	Col     int
+	// Comment is the note attached to the cell and CommentAuthor who left it
+	Comment       string
+	CommentAuthor string
+	// Link is the cell's hyperlink: a URL, or a place in the workbook written
+	// as #Sheet!A1
+	Link string
+	Row  int
+	Col  int
 }
 
 // Sheet represents a worksheet with its data
@@ -15,6 +21,178 @@ type Sheet struct {
 	MaxRows   int
 	MaxCols   int
 	ColWidths map[int]int
+	// RowHeights are the screen lines of rows given an explicit height
+	RowHeights map[int]int
+	ReadOnly   bool
+	Source     RowSource
+	// Query is the SQL the sheet was produced from; empty for sheets that
+	// belong to the file
+	Query string
+	// Sort holds the keys of the last sort, shown in the column headers
+	Sort []SortKey
+	// Filters hide the rows below FilterHeader whose cells fail any of them
+	Filters      []Filter
+	FilterHeader int
+	// FreezeRows and FreezeCols are the top rows and left columns kept on
+	// screen while the rest of the sheet scrolls
+	FreezeRows int
+	FreezeCols int
+	// HiddenRows and HiddenCols are rows and columns left out of the grid,
+	// as Excel hides them
+	HiddenRows map[int]bool
+	HiddenCols map[int]bool
+	// RowLevels and ColLevels are the outline levels of grouped rows and
+	// columns; collapsing a group hides its rows or columns
+	RowLevels map[int]int
+	ColLevels map[int]int
+	// Validations are the data validation rules of the sheet; where rules
+	// overlap the last one applies
+	Validations []Validation
+}
+
+// SortKey is one column of a sort, applied in order of precedence
+type SortKey struct {
+	Col  int
+	Desc bool
+}
+
+// FilterOp is the comparison a column filter applies to cell values
+type FilterOp int
+
+const (
+	FilterEquals FilterOp = iota
+	FilterNotEquals
+	FilterContains
+	FilterRegex
+	FilterGreater
+	FilterGreaterEq
+	FilterLess
+	FilterLessEq
+	FilterValues
+)
+
+// Filter is a criterion on one column, like an Excel autofilter. FilterValues
+// keeps rows whose cell is one of Values, or with Exclude those whose cell is
+// not; every other op compares with Value.
+type Filter struct {
+	Col     int
+	Op      FilterOp
+	Value   string
+	Values  []string
+	Exclude bool
+}
+
+// CellRange is a rectangle of cells, from the start to the end row and
+// column inclusive
+type CellRange struct {
+	StartRow int
+	StartCol int
+	EndRow   int
+	EndCol   int
+}
+
+// ValidationType is the kind of value a data validation rule allows
+type ValidationType int
+
+const (
+	ValidateList ValidationType = iota
+	ValidateWhole
+	ValidateDecimal
+	ValidateDate
+	ValidateTime
+	ValidateTextLength
+	ValidateCustom
+)
+
+// ValidationOp compares a value with the bounds of a data validation rule
+type ValidationOp int
+
+const (
+	ValidateBetween ValidationOp = iota
+	ValidateNotBetween
+	ValidateEqual
+	ValidateNotEqual
+	ValidateGreater
+	ValidateGreaterEq
+	ValidateLess
+	ValidateLessEq
+)
+
+// Validation is a data validation rule, like Excel's: the cells in Ranges
+// only take the values it allows. List rules offer Values, or the values of
+// the cells at Source; other rules compare with Min, and Max for between and
+// not between. Bounds are numbers or formulas as xlsx writes them, so dates
+// and times are serial numbers. Custom rules keep their formula in Min and
+// are saved but not checked.
+type Validation struct {
+	Ranges     []CellRange
+	Type       ValidationType
+	Op         ValidationOp
+	Min        string
+	Max        string
+	Values     []string
+	Source     string
+	AllowBlank bool
+	// NoDropDown hides the list of choices while editing
+	NoDropDown bool
+	// ErrorStyle is how values the rule does not allow are treated, named as
+	// in xlsx: stop refuses them, while warning, information and an empty
+	// style (the file shows no error) only warn. ErrorTitle and Error
+	// replace the default message.
+	ErrorStyle string
+	ErrorTitle string
+	Error      string
+	// PromptTitle and Prompt are shown when a cell under the rule is edited
+	PromptTitle string
+	Prompt      string
+}
+
+// SearchTarget selects which part of a cell search looks at
+type SearchTarget int
+
+const (
+	SearchAll SearchTarget = iota
+	SearchValues
+	SearchFormulas
+)
+
+// SearchScope selects which cells search covers
+type SearchScope int
+
+const (
+	ScopeSheet SearchScope = iota
+	ScopeColumn
+	ScopeSelection
+	ScopeWorkbook
+)
+
+// SearchOptions are the flags toggled in the search bar. Scope is applied by
+// the caller; the loader only matches cells.
+type SearchOptions struct {
+	Regex     bool
+	MatchCase bool
+	WholeCell bool
+	Target    SearchTarget
+	Scope     SearchScope
+}
+
+// SearchResult is a cell found by search
+type SearchResult struct {
+	Sheet int
+	Row   int
+	Col   int
+}
+
+// RowSource supplies the rows of a sheet on demand. Sheets backed by a
+// RowSource report their full size in MaxRows but may hold fewer Rows.
+type RowSource interface {
+	// LoadRows appends rows to sheet until it holds at least upto rows or the
+	// source is exhausted
+	LoadRows(sheet *Sheet, upto int) error
+	// Complete reports whether every row has been loaded
+	Complete() bool
+	// Close releases what the source holds open once the sheet is discarded
+	Close() error
 }
 
 // Mode represents the current application mode
@@ -31,6 +209,11 @@ const (
 	ModeSelectRange
 	ModeEdit
 	ModeSaveAs
+	ModeBuffers
+	ModeCommand
+	ModeFilter
+	ModeReplace
+	ModeReplaceConfirm
 )
 
 // StatusMsg represents a status message with type